@@ -0,0 +1,43 @@
+package orbit
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	mid "github.com/shengyanli1982/orbit/internal/middleware"
+)
+
+// 初始化管理端 Gin 引擎
+// 管理端只承载健康检查、指标、pprof 和 Swagger 等内置服务，与业务流量隔离
+func (e *Engine) initAdminEngine() {
+	if !e.config.IsAdminEnabled() {
+		return
+	}
+
+	e.adminEndpoint = net.JoinHostPort(e.config.AdminAddress, strconv.Itoa(int(e.config.AdminPort)))
+	e.adminSvr = gin.New()
+	e.adminRoot = &e.adminSvr.RouterGroup
+	e.adminSvr.HandleMethodNotAllowed = true
+	e.adminSvr.NoRoute(routeMismatchHandler)
+	e.adminSvr.NoMethod(methodNotAllowedHandler)
+	e.adminSvr.Use(routeProbe, mid.InFlight(&e.adminInflight), mid.Recovery(e.config.logger, e.config.recoveryLogEventFunc))
+}
+
+// 返回内置服务注册的路由组，启用管理端时返回管理端根路由组
+func (e *Engine) builtinRoot() *gin.RouterGroup {
+	if e.adminRoot != nil {
+		return e.adminRoot
+	}
+	return e.root
+}
+
+// 返回是否启用了独立的管理端监听
+func (e *Engine) IsAdminEnabled() bool {
+	return e.adminSvr != nil
+}
+
+// 返回管理端的 Gin 引擎，未启用管理端时返回 nil
+func (e *Engine) GetAdminGinEngine() *gin.Engine {
+	return e.adminSvr
+}
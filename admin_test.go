@@ -0,0 +1,87 @@
+package orbit
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminDisabledByDefault(t *testing.T) {
+	engine := NewEngine(NewConfig(), NewOptions())
+
+	assert.False(t, engine.IsAdminEnabled())
+	assert.Nil(t, engine.GetAdminGinEngine())
+	assert.Empty(t, engine.GetAdminEndpoint())
+}
+
+func TestAdminServesBuiltinServices(t *testing.T) {
+	config := NewConfig().WithRelease().WithAdminPort(18081)
+	engine := NewEngine(config, NewOptions().EnablePProf())
+	require.NoError(t, engine.initErr)
+	engine.RegisterService(&clientIPService{})
+	engine.Run()
+	defer engine.Stop()
+
+	assert.True(t, engine.IsAdminEnabled())
+	assert.Equal(t, "127.0.0.1:18081", engine.GetAdminEndpoint())
+
+	// 内置服务只在管理端提供
+	for _, path := range []string{com.HealthCheckURLPath, com.PprofURLPath + "/"} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		recorder := httptest.NewRecorder()
+		engine.GetAdminGinEngine().ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code, path)
+
+		req, _ = http.NewRequest(http.MethodGet, path, nil)
+		recorder = httptest.NewRecorder()
+		engine.GetGinEngine().ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusNotFound, recorder.Code, path)
+	}
+}
+
+func TestAdminListenerLifecycle(t *testing.T) {
+	config := NewConfig().WithRelease().WithPort(18080).WithAdminPort(18081)
+	engine := NewEngine(config, NewOptions().EnableMetric().EnablePProf())
+	engine.RegisterService(&clientIPService{})
+	engine.Run()
+
+	get := func(url string) (int, string) {
+		resp, err := http.Get(url)
+		if err != nil {
+			return 0, ""
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	require.Eventually(t, func() bool {
+		code, _ := get("http://127.0.0.1:18081" + com.HealthCheckURLPath)
+		return code == http.StatusOK
+	}, 2*time.Second, 20*time.Millisecond)
+
+	// 业务端只提供用户服务
+	code, _ := get("http://127.0.0.1:18080/client-ip")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = get("http://127.0.0.1:18080" + com.PprofURLPath + "/")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// 管理端的指标包含业务端请求
+	code, body := get("http://127.0.0.1:18081" + com.PromMetricURLPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `path="/client-ip"`)
+
+	code, _ = get("http://127.0.0.1:18081/client-ip")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	engine.Stop()
+
+	_, err := http.Get("http://127.0.0.1:18081" + com.HealthCheckURLPath)
+	assert.Error(t, err)
+}
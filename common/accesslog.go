@@ -0,0 +1,23 @@
+package common
+
+// 访问日志未记录的原因
+const (
+	AccessLogSampledOut = "sampled"  // 未被采样
+	AccessLogFiltered   = "filtered" // 命中过滤规则
+)
+
+// AccessLogPolicy 定义访问日志的采样与过滤规则
+// 服务端错误（5xx）、请求处理中记录的错误和慢请求始终记录，其余请求按路由的采样比例记录
+type AccessLogPolicy struct {
+	LogClientErrors  bool               `json:"logClientErrors,omitempty" yaml:"logClientErrors,omitempty"`   // 客户端错误（4xx）是否始终记录
+	SlowThreshold    uint32             `json:"slowThreshold,omitempty" yaml:"slowThreshold,omitempty"`       // 慢请求阈值（毫秒），耗时不小于阈值的请求始终记录，0 表示不启用
+	SampleRate       *float64           `json:"sampleRate,omitempty" yaml:"sampleRate,omitempty"`             // 其余请求的默认采样比例，取值 [0, 1]，0 表示不记录，nil 表示全部记录
+	RouteSampleRates map[string]float64 `json:"routeSampleRates,omitempty" yaml:"routeSampleRates,omitempty"` // 按路由模板（例如 /users/:id）设置的采样比例，取值 [0, 1]，0 表示不记录
+	SkipPaths        []string           `json:"skipPaths,omitempty" yaml:"skipPaths,omitempty"`               // 不记录的请求路径或路由模板，以 * 结尾时按前缀匹配
+	SkipMethods      []string           `json:"skipMethods,omitempty" yaml:"skipMethods,omitempty"`           // 不记录的 HTTP 方法
+}
+
+// SampleRate 返回指向采样比例的指针，用于设置 AccessLogPolicy.SampleRate
+func SampleRate(rate float64) *float64 {
+	return &rate
+}
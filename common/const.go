@@ -15,6 +15,7 @@ const (
 	HttpHeaderContentType  = "Content-Type"
 	HttpHeaderRequestID    = "X-Request-Id"
 	HttpHeaderForwardedFor = "X-Forwarded-For"
+	HttpHeaderClientIP     = "X-Orbit-Client-Ip" // 由引擎解析并写入的真实客户端 IP，请求中携带的同名头会被覆盖
 
 	// Content-Type 值
 	HttpHeaderJSONContentTypeValue       = binding.MIMEJSON
@@ -31,22 +32,40 @@ const (
 	EmptyURLPath       = ""
 	PromMetricURLPath  = "/metrics"
 	HealthCheckURLPath = "/ping"
+	LivezURLPath       = "/livez"
+	ReadyzURLPath      = "/readyz"
+	StartupzURLPath    = "/startupz"
 	RootURLPath        = "/"
 	SwaggerURLPath     = "/docs"
 	PprofURLPath       = "/debug/pprof"
+	RoutesURLPath      = "/debug/routes"
 )
 
 // 请求相关常量
 const (
 	// 请求和响应的缓冲区键
-	RequestBodyBufferKey  = "REQUEST_BODY_zdiT5HaFaMF7ZfO556rZRYqn"
-	ResponseBodyBufferKey = "RESPONSE_BODY_DT6IKLsNULVD3bTgnz1QJbeN"
-	RequestLoggerKey      = "REQUEST_LOGGER_3Z3opcTKBSe2O5yZQnSGD"
+	RequestBodyBufferKey   = "REQUEST_BODY_zdiT5HaFaMF7ZfO556rZRYqn"
+	ResponseBodyBufferKey  = "RESPONSE_BODY_DT6IKLsNULVD3bTgnz1QJbeN"
+	RequestLoggerKey       = "REQUEST_LOGGER_3Z3opcTKBSe2O5yZQnSGD"
+	PeerIdentityKey        = "PEER_IDENTITY_Qm8XbW2rTzK4nVd7hLpYs"
+	RequestServiceKey      = "REQUEST_SERVICE_Tn5WqKd8YzB2xRfLm4Hs"
+	RequestTimeoutKey      = "REQUEST_TIMEOUT_Vc7NpRw3KxJ9mYq2LdTs"
+	RequestRateLimitedKey  = "REQUEST_RATE_LIMITED_Hb4ZsQe8MwT1xNk6P"
+	RequestShedKey         = "REQUEST_SHED_Jy6TqWm2RbX8vLc4NfKp"
+	RequestIDKey           = "REQUEST_ID_Pw9KdLs3YtN7hXb2QmVr"
+	RequestTraceIDKey      = "REQUEST_TRACE_ID_Xf3MbQ8nRk5WsJc1Ty"
+	RequestSpanIDKey       = "REQUEST_SPAN_ID_Gd7VpN2sLh9KzBq4Wx"
+	RequestTraceSampledKey = "REQUEST_TRACE_SAMPLED_Rm4XcT9wKb2NfLq7"
+	RequestLogDroppedKey   = "REQUEST_LOG_DROPPED_Ks2WfN8qLx5RbTm3"
 
 	// 请求状态码和消息
-	RequestOKCode    int64 = 0
-	RequestErrorCode int64 = 10
-	RequestOK              = "success"
+	RequestOKCode      int64 = 0
+	RequestErrorCode   int64 = 10
+	RequestOK                = "success"
+	RequestDraining          = "draining"
+	RequestTimedOut          = "request timeout"
+	RequestRateLimited       = "rate limit exceeded"
+	RequestOverloaded        = "server overloaded"
 )
 
 // HTTP 服务器默认配置常量
@@ -54,6 +73,9 @@ const (
 	// 服务器关闭的默认超时时间（秒）
 	DefaultShutdownTimeoutSeconds = 10
 
+	// 服务器关闭的默认超时时间（毫秒）
+	DefaultShutdownTimeoutMillis uint32 = DefaultShutdownTimeoutSeconds * 1000
+
 	// HTTP 请求头的默认最大字节数 (2MB)
 	DefaultMaxHeaderBytes int = 1 << 21
 
@@ -63,6 +85,18 @@ const (
 	// 默认的 HTTP 监听地址和端口
 	DefaultHttpListenAddress        = "127.0.0.1"
 	DefaultHttpListenPort    uint16 = 8080
+
+	// TLS 证书文件变更检测的默认间隔（毫秒）
+	DefaultTLSReloadIntervalMillis uint32 = 5000
+
+	// 默认的最低 TLS 版本
+	DefaultTLSMinVersion = "1.2"
+
+	// 健康检查项的默认超时时间（毫秒）
+	DefaultHealthCheckTimeoutMillis uint32 = 1000
+
+	// 健康检查结果的默认缓存时间（毫秒）
+	DefaultHealthCheckCacheTTLMillis uint32 = 1000
 )
 
 // LogEventFunc 是用于记录事件的函数类型
@@ -0,0 +1,51 @@
+package common
+
+import (
+	"context"
+	"time"
+)
+
+// HealthProbe 表示健康探针的类型
+type HealthProbe string
+
+// 健康探针类型
+const (
+	HealthProbeLiveness  HealthProbe = "livez"    // 存活探针，失败时进程应被重启
+	HealthProbeReadiness HealthProbe = "readyz"   // 就绪探针，失败时不应再接收流量
+	HealthProbeStartup   HealthProbe = "startupz" // 启动探针，成功前不执行存活和就绪探针
+)
+
+// 健康检查状态
+const (
+	HealthStatusOK       = "ok"       // 检查通过
+	HealthStatusFailed   = "failed"   // 检查失败
+	HealthStatusStarting = "starting" // 引擎尚未完成启动
+)
+
+// HealthChecker 定义一个健康检查项
+type HealthChecker struct {
+	Name     string                          // 检查项名称，在同一探针中唯一
+	Timeout  time.Duration                   // 单次检查的超时时间，0 表示使用默认值
+	Critical bool                            // 是否为关键检查项，关键检查项失败时探针返回 503
+	Check    func(ctx context.Context) error // 检查函数，返回 nil 表示检查通过
+}
+
+// HealthCheckResult 描述单个检查项的执行结果
+type HealthCheckResult struct {
+	Name     string `json:"name" yaml:"name"`                       // 检查项名称
+	Status   string `json:"status" yaml:"status"`                   // 检查状态：ok、failed
+	Critical bool   `json:"critical" yaml:"critical"`               // 是否为关键检查项
+	Duration string `json:"duration" yaml:"duration"`               // 检查耗时
+	Error    string `json:"error,omitempty" yaml:"error,omitempty"` // 检查失败的原因
+}
+
+// HealthReport 描述一次探针执行的汇总结果
+type HealthReport struct {
+	Status string              `json:"status" yaml:"status"`                     // 汇总状态：ok、failed、starting、draining
+	Checks []HealthCheckResult `json:"checks,omitempty" yaml:"checks,omitempty"` // 各检查项的执行结果
+}
+
+// IsHealthy 返回探针是否通过
+func (r *HealthReport) IsHealthy() bool {
+	return r != nil && r.Status == HealthStatusOK
+}
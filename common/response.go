@@ -0,0 +1,7 @@
+package common
+
+// ErrorResponse 描述中间件中止请求时返回的 JSON 错误
+type ErrorResponse struct {
+	Code    int64  `json:"code" yaml:"code"`       // 错误码
+	Message string `json:"message" yaml:"message"` // 错误信息
+}
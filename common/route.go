@@ -0,0 +1,11 @@
+package common
+
+// RouteInfo 描述一条已注册的路由
+type RouteInfo struct {
+	Method      string   `json:"method" yaml:"method"`                               // HTTP 方法
+	Path        string   `json:"path" yaml:"path"`                                   // 路由路径
+	Handler     string   `json:"handler" yaml:"handler"`                             // 处理函数名称
+	Service     string   `json:"service,omitempty" yaml:"service,omitempty"`         // 注册该路由的服务，内置服务为 orbit
+	Middlewares []string `json:"middlewares,omitempty" yaml:"middlewares,omitempty"` // 路由生效的中间件链（不含处理函数），按执行顺序排列
+	Admin       bool     `json:"admin,omitempty" yaml:"admin,omitempty"`             // 是否注册在管理端
+}
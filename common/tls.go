@@ -0,0 +1,52 @@
+package common
+
+// TLSConfig 定义 HTTPS 服务的证书与协议配置
+type TLSConfig struct {
+	CertFile             string   `json:"certFile,omitempty" yaml:"certFile,omitempty"`                         // 证书文件路径（PEM）
+	KeyFile              string   `json:"keyFile,omitempty" yaml:"keyFile,omitempty"`                           // 私钥文件路径（PEM）
+	MinVersion           string   `json:"minVersion,omitempty" yaml:"minVersion,omitempty"`                     // 最低 TLS 版本，如 "1.2"、"1.3"
+	CipherSuites         []string `json:"cipherSuites,omitempty" yaml:"cipherSuites,omitempty"`                 // 允许的加密套件名称（仅对 TLS 1.2 及以下生效）
+	ReloadIntervalMillis uint32   `json:"reloadIntervalMillis,omitempty" yaml:"reloadIntervalMillis,omitempty"` // 证书文件变更检测间隔（毫秒）
+	ClientAuth           string   `json:"clientAuth,omitempty" yaml:"clientAuth,omitempty"`                     // 客户端证书校验模式：none、verify_if_given、require
+	ClientCAFile         string   `json:"clientCAFile,omitempty" yaml:"clientCAFile,omitempty"`                 // 用于校验客户端证书的 CA 证书包（PEM）
+}
+
+// IsEnabled 返回是否配置了证书，配置后服务器将以 HTTPS 方式提供服务
+func (c *TLSConfig) IsEnabled() bool {
+	return c != nil && c.CertFile != "" && c.KeyFile != ""
+}
+
+// IsClientAuthEnabled 返回是否启用了客户端证书校验
+func (c *TLSConfig) IsClientAuthEnabled() bool {
+	return c.IsEnabled() && c.ClientAuth != "" && c.ClientAuth != TLSClientAuthNone
+}
+
+// 客户端证书校验模式
+const (
+	TLSClientAuthNone          = "none"            // 不校验客户端证书
+	TLSClientAuthVerifyIfGiven = "verify_if_given" // 客户端提供证书时进行校验
+	TLSClientAuthRequire       = "require"         // 要求并校验客户端证书
+)
+
+// PeerIdentity 描述经过校验的客户端证书身份
+type PeerIdentity struct {
+	Subject        string   `json:"subject,omitempty" yaml:"subject,omitempty"`               // 证书主题
+	DNSNames       []string `json:"dnsNames,omitempty" yaml:"dnsNames,omitempty"`             // DNS 类型的 SAN
+	EmailAddresses []string `json:"emailAddresses,omitempty" yaml:"emailAddresses,omitempty"` // 邮箱类型的 SAN
+	IPAddresses    []string `json:"ipAddresses,omitempty" yaml:"ipAddresses,omitempty"`       // IP 类型的 SAN
+	URIs           []string `json:"uris,omitempty" yaml:"uris,omitempty"`                     // URI 类型的 SAN
+	SPIFFEID       string   `json:"spiffeId,omitempty" yaml:"spiffeId,omitempty"`             // SPIFFE ID（spiffe:// 开头的 URI SAN）
+}
+
+// SANs 返回所有主题备用名称（SAN）
+func (p *PeerIdentity) SANs() []string {
+	if p == nil {
+		return nil
+	}
+	sans := make([]string, 0, len(p.DNSNames)+len(p.EmailAddresses)+len(p.IPAddresses)+len(p.URIs))
+	sans = append(sans, p.DNSNames...)
+	sans = append(sans, p.EmailAddresses...)
+	sans = append(sans, p.IPAddresses...)
+	sans = append(sans, p.URIs...)
+	return sans
+}
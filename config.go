@@ -1,12 +1,17 @@
 package orbit
 
 import (
+	"net"
+	"os"
 	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
 	com "github.com/shengyanli1982/orbit/common"
+	mtc "github.com/shengyanli1982/orbit/internal/metric"
 	"github.com/shengyanli1982/orbit/utils/log"
+	"github.com/shengyanli1982/orbit/utils/requestid"
+	"github.com/shengyanli1982/orbit/utils/tracing"
 )
 
 // 默认配置值
@@ -15,6 +20,7 @@ var (
 	defaultHttpListenPort    = com.DefaultHttpListenPort        // 默认HTTP监听端口
 	defaultIdleTimeout       = com.DefaultHttpIdleTimeoutMillis // 默认空闲超时时间（毫秒）
 	defaultMaxHeaderBytes    = com.DefaultMaxHeaderBytes        // 默认最大头部字节数
+	defaultShutdownTimeout   = com.DefaultShutdownTimeoutMillis // 默认优雅关闭超时时间（毫秒）
 	// 默认与 Gin 保持一致：信任所有代理，按需通过 WithTrustedProxies 显式收紧
 	defaultTrustedProxies = []string{"0.0.0.0/0", "::/0"}
 	// 默认按标准代理头顺序解析真实客户端IP
@@ -35,20 +41,43 @@ var (
 // Config 结构体定义了服务器的配置选项
 type Config struct {
 	Address               string               `json:"address,omitempty" yaml:"address,omitempty"`                             // HTTP服务器监听地址
-	Port                  uint16               `json:"port,omitempty" yaml:"port,omitempty"`                                   // HTTP服务器监听端口
+	Port                  uint16               `json:"port,omitempty" yaml:"port,omitempty"`                                   // HTTP服务器监听端口（0 会被替换为默认端口并记录警告，严格模式下视为错误；由系统分配端口请使用 EphemeralPort）
+	EphemeralPort         bool                 `json:"ephemeralPort,omitempty" yaml:"ephemeralPort,omitempty"`                 // 由系统分配可用端口，设置后忽略 Port
+	UnixSocket            string               `json:"unixSocket,omitempty" yaml:"unixSocket,omitempty"`                       // Unix 域套接字路径（设置后替代 Address:Port）
+	UnixSocketMode        uint32               `json:"unixSocketMode,omitempty" yaml:"unixSocketMode,omitempty"`               // Unix 域套接字文件权限，如 0660
+	UnixSocketUser        string               `json:"unixSocketUser,omitempty" yaml:"unixSocketUser,omitempty"`               // Unix 域套接字文件属主（用户名或 UID）
+	UnixSocketGroup       string               `json:"unixSocketGroup,omitempty" yaml:"unixSocketGroup,omitempty"`             // Unix 域套接字文件属组（组名或 GID）
 	ReleaseMode           bool                 `json:"releaseMode,omitempty" yaml:"releaseMode,omitempty"`                     // 是否为发布模式
 	HttpReadTimeout       uint32               `json:"httpReadTimeout,omitempty" yaml:"httpReadTimeout,omitempty"`             // HTTP读取超时时间
 	HttpWriteTimeout      uint32               `json:"httpWriteTimeout,omitempty" yaml:"httpWriteTimeout,omitempty"`           // HTTP写入超时时间
 	HttpReadHeaderTimeout uint32               `json:"httpReadHeaderTimeout,omitempty" yaml:"httpReadHeaderTimeout,omitempty"` // HTTP读取头部超时时间
 	HttpIdleTimeout       uint32               `json:"httpIdleTimeout,omitempty" yaml:"httpIdleTimeout,omitempty"`             // HTTP空闲超时时间
+	HttpRequestTimeout    uint32               `json:"httpRequestTimeout,omitempty" yaml:"httpRequestTimeout,omitempty"`       // 业务请求处理超时时间（毫秒），超时返回 503，0 表示不限制；协议升级和 text/event-stream 请求不受限制
+	ShutdownTimeout       uint32               `json:"shutdownTimeout,omitempty" yaml:"shutdownTimeout,omitempty"`             // 优雅关闭超时时间（毫秒），超时后强制关闭剩余连接
+	PreStopDelay          uint32               `json:"preStopDelay,omitempty" yaml:"preStopDelay,omitempty"`                   // 关闭前的等待时间（毫秒），期间健康检查返回 503
+	HealthCheckCacheTTL   uint32               `json:"healthCheckCacheTTL,omitempty" yaml:"healthCheckCacheTTL,omitempty"`     // 健康检查结果缓存时间（毫秒）
 	MaxHeaderBytes        uint32               `json:"maxHeaderBytes,omitempty" yaml:"maxHeaderBytes,omitempty"`               // HTTP最大头部字节数
 	TrustedProxies        []string             `json:"trustedProxies,omitempty" yaml:"trustedProxies,omitempty"`               // 可信代理CIDR列表
 	RemoteIPHeaders       []string             `json:"remoteIPHeaders,omitempty" yaml:"remoteIPHeaders,omitempty"`             // 真实客户端IP解析头
 	CORSPolicy            *com.CORSPolicy      `json:"corsPolicy,omitempty" yaml:"corsPolicy,omitempty"`                       // CORS 策略（nil 表示使用默认策略）
+	AccessLogPolicy       *com.AccessLogPolicy `json:"accessLogPolicy,omitempty" yaml:"accessLogPolicy,omitempty"`             // 访问日志采样与过滤规则（nil 表示记录所有请求）
+	TLS                   *com.TLSConfig       `json:"tls,omitempty" yaml:"tls,omitempty"`                                     // TLS 配置（nil 表示使用 HTTP）
+	AdminAddress          string               `json:"adminAddress,omitempty" yaml:"adminAddress,omitempty"`                   // 管理端监听地址（为空时使用 Address）
+	AdminPort             uint16               `json:"adminPort,omitempty" yaml:"adminPort,omitempty"`                         // 管理端监听端口（0 表示不启用管理端）
+	LogLevel              string               `json:"logLevel,omitempty" yaml:"logLevel,omitempty"`                           // 日志级别：debug、info、warn、error（为空时由运行模式决定）
+	ServerName            string               `json:"serverName,omitempty" yaml:"serverName,omitempty"`                       // 引擎标识，非空时作为度量标准的 server 常量标签
+	MetricNamespace       string               `json:"metricNamespace,omitempty" yaml:"metricNamespace,omitempty"`             // 度量标准命名空间（为空时使用 orbit）
+	MetricSubsystem       string               `json:"metricSubsystem,omitempty" yaml:"metricSubsystem,omitempty"`             // 度量标准子系统
 	logger                *logr.Logger         `json:"-" yaml:"-"`                                                             // 日志记录器
+	levelLogger           *log.ZapLogger       `json:"-" yaml:"-"`                                                             // 用于调整日志级别的日志记录器（自定义 logr 日志记录器时为 nil）
 	accessLogEventFunc    com.LogEventFunc     `json:"-" yaml:"-"`                                                             // 访问日志事件处理函数
 	recoveryLogEventFunc  com.LogEventFunc     `json:"-" yaml:"-"`                                                             // 恢复日志事件处理函数
+	requestIDGenerator    requestid.Generator  `json:"-" yaml:"-"`                                                             // 请求 ID 生成函数
+	tracer                *tracing.Tracer      `json:"-" yaml:"-"`                                                             // 链路追踪器（nil 表示不启用链路追踪）
 	prometheusRegistry    *prometheus.Registry `json:"-" yaml:"-"`                                                             // Prometheus注册表
+	listener              net.Listener         `json:"-" yaml:"-"`                                                             // 外部注入的监听器
+	signalCh              <-chan os.Signal     `json:"-" yaml:"-"`                                                             // 外部注入的信号通道
+	portDefaulted         bool                 `json:"-" yaml:"-"`                                                             // Port 为 0 且未设置 EphemeralPort，已替换为默认端口
 }
 
 // 创建并返回一个新的默认配置实例
@@ -61,20 +90,39 @@ func NewConfig() *Config {
 		HttpWriteTimeout:      defaultIdleTimeout,
 		HttpReadHeaderTimeout: defaultIdleTimeout,
 		HttpIdleTimeout:       defaultIdleTimeout,
+		ShutdownTimeout:       defaultShutdownTimeout,
+		HealthCheckCacheTTL:   com.DefaultHealthCheckCacheTTLMillis,
 		MaxHeaderBytes:        uint32(defaultMaxHeaderBytes),
 		TrustedProxies:        cloneStringSlice(defaultTrustedProxies),
 		RemoteIPHeaders:       cloneStringSlice(defaultRemoteIPHeaders),
 		CORSPolicy:            cloneCORSPolicyPtr(&defaultCORSPolicy),
 		logger:                &com.DefaultLogrLogger,
+		levelLogger:           com.DefaultConsoleLogger,
 		accessLogEventFunc:    log.DefaultAccessEventFunc,
 		recoveryLogEventFunc:  log.DefaultRecoveryEventFunc,
+		requestIDGenerator:    requestid.NewUUIDv4,
 		prometheusRegistry:    prometheus.DefaultRegisterer.(*prometheus.Registry),
 	}
 }
 
 // 设置日志记录器
+// 自定义的 logr 日志记录器无法调整日志级别，LogLevel 对其不生效
 func (c *Config) WithLogger(logger *logr.Logger) *Config {
 	c.logger = logger
+	c.levelLogger = nil
+	return c
+}
+
+// 设置 Zap 日志记录器，LogLevel 通过它在启动和热更新时生效
+func (c *Config) WithZapLogger(logger *log.ZapLogger) *Config {
+	c.logger = logger.GetLogrLogger()
+	c.levelLogger = logger
+	return c
+}
+
+// 设置日志级别
+func (c *Config) WithLogLevel(level string) *Config {
+	c.LogLevel = level
 	return c
 }
 
@@ -84,9 +132,43 @@ func (c *Config) WithAddress(address string) *Config {
 	return c
 }
 
-// 设置HTTP监听端口
+// 设置HTTP监听端口，端口为 0 时使用默认端口并记录警告，严格模式下视为错误
+// 需要由系统分配可用端口时使用 WithEphemeralPort
 func (c *Config) WithPort(port uint16) *Config {
 	c.Port = port
+	c.EphemeralPort = false
+	c.portDefaulted = false
+	return c
+}
+
+// 由系统分配可用端口，实际地址可通过 GetListenEndpoint 获取
+func (c *Config) WithEphemeralPort() *Config {
+	c.Port = 0
+	c.EphemeralPort = true
+	c.portDefaulted = false
+	return c
+}
+
+// 设置 Unix 域套接字路径，设置后服务器在该套接字上监听而不是 Address:Port
+// mode 为套接字文件权限（0 表示保持默认），owner 和 group 为空表示保持默认属主
+func (c *Config) WithUnixSocket(path string, mode uint32, owner, group string) *Config {
+	c.UnixSocket = path
+	c.UnixSocketMode = mode
+	c.UnixSocketUser = owner
+	c.UnixSocketGroup = group
+	return c
+}
+
+// 设置外部创建的监听器，设置后服务器直接在该监听器上提供服务
+// 监听器的所有权转交给引擎，引擎停止时会将其关闭
+func (c *Config) WithListener(listener net.Listener) *Config {
+	c.listener = listener
+	return c
+}
+
+// 设置外部注入的信号通道，启用信号处理后引擎从该通道读取信号，而不是监听进程信号
+func (c *Config) WithSignalChannel(ch <-chan os.Signal) *Config {
+	c.signalCh = ch
 	return c
 }
 
@@ -120,6 +202,33 @@ func (c *Config) WithHttpIdleTimeout(timeout uint32) *Config {
 	return c
 }
 
+// 设置业务请求的处理超时时间（毫秒），作用于所有用户服务的路由，超时后返回 503
+// 单个路由或路由组可以使用 middleware.Timeout 设置更短的超时时间
+// 响应在处理结束前被缓存，协议升级（如 WebSocket）和 text/event-stream 请求不受超时限制
+func (c *Config) WithHttpRequestTimeout(timeout uint32) *Config {
+	c.HttpRequestTimeout = timeout
+	return c
+}
+
+// 设置优雅关闭的超时时间（毫秒），超时后仍未完成的请求将被强制中断
+func (c *Config) WithShutdownTimeout(timeout uint32) *Config {
+	c.ShutdownTimeout = timeout
+	return c
+}
+
+// 设置关闭前的等待时间（毫秒）
+// 等待期间引擎被标记为未就绪，健康检查返回 503，便于负载均衡摘除流量
+func (c *Config) WithPreStopDelay(delay uint32) *Config {
+	c.PreStopDelay = delay
+	return c
+}
+
+// 设置健康检查结果的缓存时间（毫秒），缓存时间内的探针请求复用上一次的检查结果
+func (c *Config) WithHealthCheckCacheTTL(ttl uint32) *Config {
+	c.HealthCheckCacheTTL = ttl
+	return c
+}
+
 // 设置HTTP最大头部字节数
 func (c *Config) WithMaxHeaderBytes(bytes uint32) *Config {
 	c.MaxHeaderBytes = bytes
@@ -144,6 +253,40 @@ func (c *Config) WithCORSPolicy(policy com.CORSPolicy) *Config {
 	return c
 }
 
+// 设置访问日志采样与过滤规则
+func (c *Config) WithAccessLogPolicy(policy com.AccessLogPolicy) *Config {
+	c.AccessLogPolicy = cloneAccessLogPolicyPtr(&policy)
+	return c
+}
+
+// 设置 TLS 配置，配置证书后服务器将以 HTTPS 方式提供服务
+func (c *Config) WithTLS(conf com.TLSConfig) *Config {
+	c.TLS = cloneTLSConfigPtr(&conf)
+	return c
+}
+
+// 设置管理端监听地址
+func (c *Config) WithAdminAddress(address string) *Config {
+	c.AdminAddress = address
+	return c
+}
+
+// 设置管理端监听端口，设置后内置服务将在独立端口上提供
+func (c *Config) WithAdminPort(port uint16) *Config {
+	c.AdminPort = port
+	return c
+}
+
+// 返回是否启用了独立的管理端监听
+func (c *Config) IsAdminEnabled() bool {
+	return c.AdminPort != 0
+}
+
+// 是否在 Address:Port 上监听，使用 Unix 域套接字或外部注入的监听器时不使用 Port
+func (c *Config) listensOnPort() bool {
+	return c.UnixSocket == "" && c.listener == nil
+}
+
 // 设置访问日志事件处理函数
 func (c *Config) WithAccessLogEventFunc(fn com.LogEventFunc) *Config {
 	c.accessLogEventFunc = fn
@@ -156,12 +299,42 @@ func (c *Config) WithRecoveryLogEventFunc(fn com.LogEventFunc) *Config {
 	return c
 }
 
+// 设置请求 ID 生成函数，默认生成 UUIDv4，也可以使用 requestid.NewUUIDv7、requestid.NewULID 或自定义函数
+func (c *Config) WithRequestIDGenerator(fn requestid.Generator) *Config {
+	c.requestIDGenerator = fn
+	return c
+}
+
+// 设置链路追踪器，启用后为每个请求创建服务端 Span 并传播 W3C Trace Context
+func (c *Config) WithTracer(tracer *tracing.Tracer) *Config {
+	c.tracer = tracer
+	return c
+}
+
+// 设置引擎标识，多个引擎共享同一个 Prometheus 注册表时用于区分各自的度量标准
+func (c *Config) WithServerName(name string) *Config {
+	c.ServerName = name
+	return c
+}
+
+// 设置度量标准的命名空间和子系统
+func (c *Config) WithMetricNamespace(namespace, subsystem string) *Config {
+	c.MetricNamespace = namespace
+	c.MetricSubsystem = subsystem
+	return c
+}
+
 // 设置Prometheus注册表
 func (c *Config) WithPrometheusRegistry(registry *prometheus.Registry) *Config {
 	c.prometheusRegistry = registry
 	return c
 }
 
+// 返回度量标准的命名选项
+func (c *Config) metricOptions() mtc.Options {
+	return mtc.Options{Namespace: c.MetricNamespace, Subsystem: c.MetricSubsystem, Server: c.ServerName}
+}
+
 // 返回默认配置实例
 func DefaultConfig() *Config {
 	return NewConfig()
@@ -181,8 +354,15 @@ func isConfigValid(conf *Config) *Config {
 	if strings.TrimSpace(conf.Address) == "" {
 		conf.Address = defaultConf.Address
 	}
-	if conf.Port == 0 {
+	// 显式要求由系统分配端口时端口为 0，否则端口 0 替换为默认端口，并标记以便记录警告和严格模式校验
+	if conf.EphemeralPort {
+		conf.Port = 0
+	} else if conf.Port == 0 {
 		conf.Port = defaultConf.Port
+		conf.portDefaulted = conf.listensOnPort()
+	}
+	if conf.IsAdminEnabled() && strings.TrimSpace(conf.AdminAddress) == "" {
+		conf.AdminAddress = conf.Address
 	}
 
 	// 验证并设置超时配置
@@ -198,6 +378,12 @@ func isConfigValid(conf *Config) *Config {
 	if conf.HttpIdleTimeout == 0 {
 		conf.HttpIdleTimeout = defaultConf.HttpIdleTimeout
 	}
+	if conf.ShutdownTimeout == 0 {
+		conf.ShutdownTimeout = defaultConf.ShutdownTimeout
+	}
+	if conf.HealthCheckCacheTTL == 0 {
+		conf.HealthCheckCacheTTL = defaultConf.HealthCheckCacheTTL
+	}
 	if conf.MaxHeaderBytes == 0 {
 		conf.MaxHeaderBytes = defaultConf.MaxHeaderBytes
 	}
@@ -212,14 +398,20 @@ func isConfigValid(conf *Config) *Config {
 		conf.RemoteIPHeaders = cloneStringSlice(conf.RemoteIPHeaders)
 	}
 	conf.CORSPolicy = normalizeCORSPolicy(conf.CORSPolicy, defaultConf.CORSPolicy)
+	conf.AccessLogPolicy = cloneAccessLogPolicyPtr(conf.AccessLogPolicy)
+	conf.TLS = normalizeTLSConfig(conf.TLS)
 
 	// 验证并设置日志和事件处理配置
 	if conf.logger == nil {
 		conf.logger = defaultConf.logger
+		conf.levelLogger = defaultConf.levelLogger
 	}
 	if conf.accessLogEventFunc == nil {
 		conf.accessLogEventFunc = defaultConf.accessLogEventFunc
 	}
+	if conf.requestIDGenerator == nil {
+		conf.requestIDGenerator = defaultConf.requestIDGenerator
+	}
 	if conf.recoveryLogEventFunc == nil {
 		conf.recoveryLogEventFunc = defaultConf.recoveryLogEventFunc
 	}
@@ -306,3 +498,53 @@ func normalizeCORSPolicy(current, fallback *com.CORSPolicy) *com.CORSPolicy {
 
 	return &merged
 }
+
+// cloneAccessLogPolicyPtr 复制访问日志策略指针
+// 返回一个新的指针，指向复制后的访问日志策略
+func cloneAccessLogPolicyPtr(policy *com.AccessLogPolicy) *com.AccessLogPolicy {
+	if policy == nil {
+		return nil
+	}
+	cp := *policy
+	if policy.SampleRate != nil {
+		rate := *policy.SampleRate
+		cp.SampleRate = &rate
+	}
+	if policy.RouteSampleRates != nil {
+		cp.RouteSampleRates = make(map[string]float64, len(policy.RouteSampleRates))
+		for route, rate := range policy.RouteSampleRates {
+			cp.RouteSampleRates[route] = rate
+		}
+	}
+	cp.SkipPaths = cloneStringSlice(policy.SkipPaths)
+	cp.SkipMethods = cloneStringSlice(policy.SkipMethods)
+	return &cp
+}
+
+// cloneTLSConfigPtr 复制 TLS 配置指针
+// 返回一个新的指针，指向复制后的 TLS 配置
+func cloneTLSConfigPtr(conf *com.TLSConfig) *com.TLSConfig {
+	if conf == nil {
+		return nil
+	}
+	cp := *conf
+	cp.CipherSuites = cloneStringSlice(conf.CipherSuites)
+	return &cp
+}
+
+// normalizeTLSConfig 规范化 TLS 配置
+// 复制配置并为未设置的字段填充默认值
+func normalizeTLSConfig(conf *com.TLSConfig) *com.TLSConfig {
+	if conf == nil {
+		return nil
+	}
+
+	normalized := cloneTLSConfigPtr(conf)
+	if strings.TrimSpace(normalized.MinVersion) == "" {
+		normalized.MinVersion = com.DefaultTLSMinVersion
+	}
+	if normalized.ReloadIntervalMillis == 0 {
+		normalized.ReloadIntervalMillis = com.DefaultTLSReloadIntervalMillis
+	}
+	return normalized
+}
@@ -92,3 +92,53 @@ func TestConfigValidationUsesDefaultCORSPolicyWhenNil(t *testing.T) {
 	assert.True(t, config.CORSPolicy.Enabled)
 	assert.Equal(t, []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}, config.CORSPolicy.AllowedMethods)
 }
+
+func TestConfigWithTLSCloneInputAndDefaults(t *testing.T) {
+	tlsConf := com.TLSConfig{
+		CertFile:     "server.crt",
+		KeyFile:      "server.key",
+		CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+	}
+
+	config := isConfigValid(NewConfig().WithTLS(tlsConf))
+	tlsConf.CipherSuites[0] = "changed"
+
+	assert.NotNil(t, config.TLS)
+	assert.True(t, config.TLS.IsEnabled())
+	assert.Equal(t, []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}, config.TLS.CipherSuites)
+	assert.Equal(t, com.DefaultTLSMinVersion, config.TLS.MinVersion)
+	assert.Equal(t, com.DefaultTLSReloadIntervalMillis, config.TLS.ReloadIntervalMillis)
+}
+
+func TestConfigAdminAddressDefaultsToAddress(t *testing.T) {
+	config := isConfigValid(NewConfig().WithAddress("0.0.0.0").WithAdminPort(9090))
+	assert.True(t, config.IsAdminEnabled())
+	assert.Equal(t, "0.0.0.0", config.AdminAddress)
+
+	config = isConfigValid(NewConfig().WithAdminAddress("127.0.0.1").WithAdminPort(9090))
+	assert.Equal(t, "127.0.0.1", config.AdminAddress)
+
+	config = isConfigValid(NewConfig())
+	assert.False(t, config.IsAdminEnabled())
+	assert.Empty(t, config.AdminAddress)
+}
+
+func TestConfigValidationKeepsEphemeralPort(t *testing.T) {
+	config := isConfigValid(NewConfig().WithEphemeralPort())
+	assert.Equal(t, uint16(0), config.Port)
+
+	// 未显式要求时，未设置的端口使用默认端口
+	assert.Equal(t, uint16(8080), isConfigValid(&Config{}).Port)
+	assert.Equal(t, uint16(8080), isConfigValid(NewConfig().WithPort(0)).Port)
+	assert.Equal(t, uint16(9000), isConfigValid(NewConfig().WithEphemeralPort().WithPort(9000)).Port)
+}
+
+func TestConfigShutdownDefaults(t *testing.T) {
+	conf := isConfigValid(&Config{})
+	assert.Equal(t, com.DefaultShutdownTimeoutMillis, conf.ShutdownTimeout)
+	assert.Equal(t, uint32(0), conf.PreStopDelay)
+
+	conf = NewConfig().WithShutdownTimeout(3000).WithPreStopDelay(5000)
+	assert.Equal(t, uint32(3000), conf.ShutdownTimeout)
+	assert.Equal(t, uint32(5000), conf.PreStopDelay)
+}
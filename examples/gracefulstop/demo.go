@@ -1,10 +1,10 @@
 package main
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/shengyanli1982/gs"
 	"github.com/shengyanli1982/orbit"
 )
 
@@ -25,13 +25,9 @@ func (s *service) RegisterGroup(g *gin.RouterGroup) {
 }
 
 func main() {
-	// 创建一个新的 TerminateSignal 实例
-	// Create a new TerminateSignal instance
-	sig := gs.NewTerminateSignal()
-
-	// 创建一个新的 Orbit 功能选项
-	// Create a new Orbit feature options
-	opts := orbit.NewOptions()
+	// 创建一个新的 Orbit 功能选项，并启用系统信号处理
+	// Create a new Orbit feature options and enable OS signal handling
+	opts := orbit.NewOptions().EnableSignalHandling()
 
 	// 创建一个新的 Orbit 引擎
 	// Create a new Orbit engine
@@ -41,15 +37,16 @@ func main() {
 	// Register a custom router group
 	engine.RegisterService(&service{})
 
-	// 启动引擎
-	// Start the engine
-	engine.Run()
-
-	// 注册需要在终止信号发生时执行的处理函数
-	// Register the handle functions to be executed when the termination signal occurs
-	sig.RegisterCancelHandles(engine.Stop)
+	// 注册 SIGHUP 触发的重载函数
+	// Register the reload function triggered by SIGHUP
+	engine.RegisterReloadFunc(func(ctx context.Context) error {
+		engine.GetLogger().Info("reloading")
+		return nil
+	})
 
-	// 等待所有的异步关闭信号
-	// Wait for all asynchronous shutdown signals
-	gs.WaitForAsync(sig)
+	// 启动引擎并阻塞，收到 SIGINT/SIGTERM 后优雅关闭，再次收到终止信号时强制退出
+	// Start the engine and block; SIGINT/SIGTERM trigger a graceful shutdown, a second termination signal forces exit
+	if err := engine.RunContext(context.Background()); err != nil {
+		engine.GetLogger().Error(err, "engine exited with error")
+	}
 }
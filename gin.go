@@ -2,8 +2,12 @@ package orbit
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -13,41 +17,90 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
 	com "github.com/shengyanli1982/orbit/common"
+	"github.com/shengyanli1982/orbit/internal/health"
+	"github.com/shengyanli1982/orbit/internal/listener"
 	ilog "github.com/shengyanli1982/orbit/internal/log"
 	mtc "github.com/shengyanli1982/orbit/internal/metric"
 	mid "github.com/shengyanli1982/orbit/internal/middleware"
+	"github.com/shengyanli1982/orbit/internal/tlsutil"
+	umid "github.com/shengyanli1982/orbit/utils/middleware"
+	"github.com/shengyanli1982/orbit/utils/tracing"
 )
 
-// 默认的服务器关闭超时时间
-var defaultShutdownTimeout = time.Second * com.DefaultShutdownTimeoutSeconds
-
 // HTTP 连接的默认空闲超时时间（秒）
 const defaultHttpIdleTimeoutSeconds = int(com.DefaultHttpIdleTimeoutMillis / 1000)
 
+// 服务器名称，用于日志中区分业务端与管理端
+const (
+	httpServerName  = "http"
+	adminServerName = "admin"
+)
+
 // Service 接口定义了注册路由组的方法
 type Service interface {
 	RegisterGroup(routerGroup *gin.RouterGroup)
 }
 
+// NamedService 可由 Service 实现，返回服务名称
+// 名称用于标记服务的路由、度量标准（service 标签）和访问日志，为空时视为未命名
+type NamedService interface {
+	Name() string
+}
+
+// BasePathService 可由 Service 实现，返回服务路由的公共前缀，引擎以该前缀创建路由组传给 RegisterGroup
+type BasePathService interface {
+	BasePath() string
+}
+
+// MiddlewareService 可由 Service 实现，返回只作用于该服务路由的中间件，按顺序添加到服务的路由组
+type MiddlewareService interface {
+	Middlewares() []gin.HandlerFunc
+}
+
 // Engine 结构体是 Orbit 框架的核心引擎，包含了 HTTP 服务器和相关配置
 type Engine struct {
-	endpoint string
-	ginSvr   *gin.Engine
-	httpSvr  *http.Server
-	root     *gin.RouterGroup
-	config   *Config
-	opts     *Options
-	running  atomic.Bool
-	wg       sync.WaitGroup
-	once     sync.Once
-	ctx      context.Context
-	cancel   context.CancelFunc
-	handlers []gin.HandlerFunc
-	services []Service
-	metric   *mtc.ServerMetrics
-	initErr  error
-	runErrMu sync.Mutex
-	runErr   error
+	endpoint      string
+	listener      net.Listener
+	ginSvr        *gin.Engine
+	httpSvr       *http.Server
+	root          *gin.RouterGroup
+	adminEndpoint string
+	adminListener net.Listener
+	adminSvr      *gin.Engine
+	adminHttpSvr  *http.Server
+	adminRoot     *gin.RouterGroup
+	config        *Config
+	opts          *Options
+	state         atomic.Int32
+	stateMu       sync.Mutex
+	stopped       chan struct{}
+	wg            sync.WaitGroup
+	ctx           context.Context
+	cancel        context.CancelFunc
+	handlers      []gin.HandlerFunc
+	services      []Service
+	started       []Service
+	metric        *mtc.ServerMetrics
+	healthMetric  *mtc.HealthMetrics
+	probers       map[com.HealthProbe]*health.Prober
+	tlsConfig     *tls.Config
+	certReloader  *tlsutil.CertReloader
+	initErr       error
+	runErrMu      sync.Mutex
+	runErr        error
+	serveErrCh    chan error
+	ready         chan struct{}
+	injectedUsed  bool
+	routesApplied bool
+	starting      bool
+	draining      atomic.Bool
+	inflight      atomic.Int64
+	adminInflight atomic.Int64
+	live          atomic.Pointer[liveConfig]
+	routeMetas    map[routeKey]routeMeta
+	reloadMu      sync.Mutex
+	reloadFuncs   []func(context.Context) error
+	exitFunc      func(code int)
 }
 
 // NewEngine 创建并返回一个新的引擎实例
@@ -64,12 +117,28 @@ func NewEngine(config *Config, options *Options) *Engine {
 
 	// 创建引擎实例并初始化基本属性
 	engine := &Engine{
-		endpoint: fmt.Sprintf("%s:%d", config.Address, config.Port), // 设置服务器监听地址
-		config:   config,
-		opts:     options,
-		handlers: make([]gin.HandlerFunc, 0, 10),
-		services: make([]Service, 0, 10),
-		metric:   mtc.NewServerMetrics(config.prometheusRegistry),
+		endpoint:     configuredEndpoint(config), // 设置服务器监听地址
+		config:       config,
+		opts:         options,
+		handlers:     make([]gin.HandlerFunc, 0, 10),
+		services:     make([]Service, 0, 10),
+		metric:       mtc.NewServerMetricsWithOptions(config.prometheusRegistry, config.metricOptions()),
+		healthMetric: mtc.NewHealthMetricsWithOptions(config.prometheusRegistry, config.metricOptions()),
+		ready:        make(chan struct{}),
+		exitFunc:     os.Exit,
+	}
+
+	// 初始化健康探针
+	engine.initHealthProbes()
+
+	// 端口 0 被替换为默认端口时提示使用 EphemeralPort
+	if config.portDefaulted {
+		config.logger.Info("port 0 is replaced with the default port, use ephemeralPort to let the system choose a port", "port", config.Port)
+	}
+
+	// 设置日志级别
+	if err := engine.applyLogLevel(config.LogLevel); err != nil {
+		config.logger.Error(err, "failed to set log level, keep current level", "level", config.LogLevel)
 	}
 
 	// 创建可取消的上下文，用于服务器生命周期管理
@@ -78,9 +147,26 @@ func NewEngine(config *Config, options *Options) *Engine {
 	// 初始化 Gin 引擎并设置基本配置
 	engine.initErr = engine.initGinEngine(options)
 
+	// 严格模式下校验配置，一次性报告所有问题并拒绝启动
+	if options.strictConfig {
+		if err := config.Validate(); err != nil {
+			engine.initErr = err
+		}
+	}
+
+	// 初始化 TLS 配置，加载证书
+	if engine.initErr == nil {
+		engine.initErr = engine.initTLS()
+	}
+
+	// 初始化管理端 Gin 引擎
+	if engine.initErr == nil {
+		engine.initAdminEngine()
+	}
+
 	if engine.initErr == nil {
 		// 注册内置服务（健康检查、Swagger、Pprof、指标收集等）
-		engine.registerBuiltinServices()
+		engine.initErr = engine.registerBuiltinServices()
 	}
 
 	return engine
@@ -97,11 +183,20 @@ func (e *Engine) initGinEngine(options *Options) error {
 		if err := e.ginSvr.SetTrustedProxies(cloneStringSlice(e.config.TrustedProxies)); err != nil {
 			return fmt.Errorf("failed to set trusted proxies %v: %w", e.config.TrustedProxies, err)
 		}
+		// 真实客户端 IP 由 ClientIP 中间件按运行时配置解析，使可信代理支持热更新
+		e.ginSvr.TrustedPlatform = com.HttpHeaderClientIP
 	}
 	e.ginSvr.RedirectTrailingSlash = options.trailingSlash
 	e.ginSvr.RedirectFixedPath = options.fixedPath
 	e.ginSvr.HandleMethodNotAllowed = true
 
+	// 创建运行时配置快照
+	live, err := newLiveConfig(e.config, options.forwordByClientIp)
+	if err != nil {
+		return err
+	}
+	e.live.Store(live)
+
 	e.setupBaseHandlers()
 	return nil
 }
@@ -109,86 +204,261 @@ func (e *Engine) initGinEngine(options *Options) error {
 // 设置基本的 HTTP 处理函数，包括 404、405 处理和中间件
 func (e *Engine) setupBaseHandlers() {
 	// 设置 404 路由未匹配的处理函数
-	e.ginSvr.NoRoute(func(c *gin.Context) {
-		var sb strings.Builder
-		sb.WriteString("[404] http request route mismatch, method: ")
-		sb.WriteString(c.Request.Method)
-		sb.WriteString(", path: ")
-		sb.WriteString(c.Request.URL.Path)
-		c.String(http.StatusNotFound, sb.String())
-	})
+	e.ginSvr.NoRoute(routeMismatchHandler)
 
 	// 设置 405 方法不允许的处理函数
-	e.ginSvr.NoMethod(func(c *gin.Context) {
-		var sb strings.Builder
-		sb.WriteString("[405] http request method not allowed, method: ")
-		sb.WriteString(c.Request.Method)
-		sb.WriteString(", path: ")
-		sb.WriteString(c.Request.URL.Path)
-		c.String(http.StatusMethodNotAllowed, sb.String())
-	})
+	e.ginSvr.NoMethod(methodNotAllowedHandler)
+
+	// 注册路由探测中间件，必须位于所有中间件的最前面
+	e.ginSvr.Use(routeProbe)
 
 	// 注册基本中间件
 	e.ginSvr.Use(
+		mid.InFlight(&e.inflight),                                    // 进行中请求计数中间件
 		mid.Recovery(e.config.logger, e.config.recoveryLogEventFunc), // 恢复中间件
-		mid.BodyBuffer(),                         // 请求体缓冲中间件
-		mid.CorsWithPolicy(*e.config.CORSPolicy), // CORS 中间件
+		mid.BodyBuffer(),                                             // 请求体缓冲中间件
+		e.corsHandler,                                                // CORS 中间件
 	)
-}
 
-// 注册内置的服务，包括健康检查、Swagger、pprof 和指标收集等
-func (e *Engine) registerBuiltinServices() {
-	// 根据配置注册可选服务
-	if e.opts.healthCheck {
-		healthcheckService(e.root.Group(com.HealthCheckURLPath)) // 注册健康检查服务
+	// 启用请求 ID 时，为请求分配请求 ID
+	if e.opts.requestID {
+		e.ginSvr.Use(mid.RequestID(e.config.requestIDGenerator))
 	}
-	if e.opts.swagger {
-		swaggerService(e.root.Group(com.SwaggerURLPath)) // 注册 Swagger 服务
+
+	// 配置链路追踪器时，为请求创建服务端 Span
+	if e.config.tracer != nil {
+		e.ginSvr.Use(tracing.Middleware(e.config.tracer))
+	}
+
+	// 启用客户端 IP 转发时，按运行时配置解析真实客户端 IP
+	if e.opts.forwordByClientIp {
+		e.ginSvr.Use(mid.ClientIP(e.clientIPPolicy))
 	}
-	if e.opts.pprof {
-		pprofService(e.root.Group(com.PprofURLPath)) // 注册 pprof 服务
+
+	// 启用客户端证书校验时，提取客户端身份
+	if e.config.TLS.IsClientAuthEnabled() {
+		e.ginSvr.Use(mid.PeerIdentity())
 	}
+}
+
+// 处理路由未匹配的请求，返回 404
+func routeMismatchHandler(c *gin.Context) {
+	var sb strings.Builder
+	sb.WriteString("[404] http request route mismatch, method: ")
+	sb.WriteString(c.Request.Method)
+	sb.WriteString(", path: ")
+	sb.WriteString(c.Request.URL.Path)
+	c.String(http.StatusNotFound, sb.String())
+}
+
+// 处理方法不允许的请求，返回 405
+func methodNotAllowedHandler(c *gin.Context) {
+	var sb strings.Builder
+	sb.WriteString("[405] http request method not allowed, method: ")
+	sb.WriteString(c.Request.Method)
+	sb.WriteString(", path: ")
+	sb.WriteString(c.Request.URL.Path)
+	c.String(http.StatusMethodNotAllowed, sb.String())
+}
+
+// 注册内置的服务，包括健康检查、Swagger、pprof 和指标收集等
+// 配置了管理端口时，内置服务注册到管理端，否则与业务路由共用同一端口
+func (e *Engine) registerBuiltinServices() error {
+	root := e.builtinRoot()
+
+	// 根据配置注册可选服务，并记录路由所属的服务
+	e.trackRoutes(e.builtinServer(), builtinServiceName, func() {
+		if e.opts.healthCheck {
+			healthcheckService(root.Group(com.HealthCheckURLPath), e.IsDraining) // 注册健康检查服务
+			e.registerProbeServices(root)                                        // 注册存活、就绪和启动探针
+		}
+		if e.opts.swagger {
+			swaggerService(root.Group(com.SwaggerURLPath)) // 注册 Swagger 服务
+		}
+		if e.opts.pprof {
+			pprofService(root.Group(com.PprofURLPath)) // 注册 pprof 服务
+		}
+		if e.opts.routes {
+			e.routesService(root.Group(com.RoutesURLPath)) // 注册路由表服务
+		}
+	})
 	if e.opts.metric {
-		e.setupMetricService() // 注册指标收集服务
+		return e.setupMetricService(root) // 注册指标收集服务
 	}
+	return nil
 }
 
 // 设置并注册 Prometheus 指标收集服务
-func (e *Engine) setupMetricService() {
-	e.metric.Register()                                                                              // 注册指标收集器
-	e.ginSvr.Use(e.metric.HandlerFunc(e.config.logger))                                              // 添加指标收集中间件
-	metricService(e.root.Group(com.PromMetricURLPath), e.config.prometheusRegistry, e.config.logger) // 注册指标服务路由
+// 与注册表中已有的度量标准冲突时返回错误，多个引擎共享注册表时需要通过 Config.WithServerName 区分
+func (e *Engine) setupMetricService(root *gin.RouterGroup) error {
+	if err := e.metric.Register(); err != nil {
+		return err
+	}
+	if err := e.healthMetric.Register(); err != nil {
+		e.metric.Unregister()
+		return err
+	}
+	e.ginSvr.Use(e.metric.HandlerFunc(e.config.logger)) // 添加指标收集中间件
+	e.trackRoutes(e.builtinServer(), builtinServiceName, func() {
+		metricService(root.Group(com.PromMetricURLPath), e.config.prometheusRegistry, e.config.logger) // 注册指标服务路由
+	})
+	return nil
 }
 
-// 启动 HTTP 服务器
+// 启动 HTTP 服务器，启动失败时只记录日志
+// 需要获取启动错误或阻塞等待服务器退出时，请使用 RunContext
 func (e *Engine) Run() {
+	if err := e.start(context.Background()); err != nil && !errors.Is(err, ErrEngineRunning) {
+		e.config.logger.Error(err, "failed to start engine, startup aborted", "address", e.endpoint)
+	}
+}
+
+// RunContext 启动 HTTP 服务器并阻塞，直到 ctx 被取消、服务器运行失败或引擎被停止
+// 启动失败时直接返回错误；ctx 取消或引擎被停止时返回 nil，服务器运行失败时返回对应错误
+// 返回前会优雅地停止引擎
+func (e *Engine) RunContext(ctx context.Context) error {
+	if err := e.start(ctx); err != nil {
+		return err
+	}
+	defer e.Stop()
+
+	return e.wait(ctx)
+}
+
+// 等待本次运行结束：ctx 取消或引擎被停止时返回 nil，服务器运行失败时返回对应错误
+func (e *Engine) wait(ctx context.Context) error {
+	e.stateMu.Lock()
+	runCtx, serveErrCh := e.ctx, e.serveErrCh
+	e.stateMu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-runCtx.Done():
+		return nil
+	case err := <-serveErrCh:
+		return err
+	}
+}
+
+// Ready 返回一个通道，监听器绑定完成、服务器开始提供服务后该通道被关闭
+// 引擎停止后会生成新的通道，用于等待下一次启动
+func (e *Engine) Ready() <-chan struct{} {
+	e.stateMu.Lock()
+	defer e.stateMu.Unlock()
+	return e.ready
+}
+
+// 调用服务的启动钩子，创建监听器并启动 HTTP 服务器，返回启动过程中的错误
+func (e *Engine) start(ctx context.Context) error {
 	if e.initErr != nil {
-		e.config.logger.Error(e.initErr, "engine initialization failed, startup aborted")
-		return
+		return e.initErr
 	}
 
-	// 检查服务器是否已经在运行
-	if e.IsRunning() {
-		return
+	e.stateMu.Lock()
+	defer e.stateMu.Unlock()
+
+	// 检查生命周期状态，只有 new 和 stopped 状态可以启动
+	switch {
+	case e.starting:
+		return ErrEngineStarting
+	case e.State() == EngineStateRunning:
+		return ErrEngineRunning
+	case e.State() == EngineStateStopping:
+		return ErrEngineStopping
 	}
 
-	// 注册用户中间件和服务
+	// 上一次运行已注册过用户中间件和路由，重新构建 Gin 引擎，避免重复注册
+	if e.routesApplied {
+		if err := e.rebuildGinEngines(); err != nil {
+			return err
+		}
+		e.routesApplied = false
+	}
+
+	// 按注册顺序调用服务的启动钩子，任一服务启动失败时终止启动
+	// 钩子执行期间释放 stateMu，钩子中可以调用 Routes、Ready、ApplyConfig 等方法
+	services := append([]Service(nil), e.services...)
+	e.starting = true
+	e.stateMu.Unlock()
+	started, err := e.runStartHooks(ctx, services)
+	e.stateMu.Lock()
+	e.starting = false
+	if err != nil {
+		return e.abortStart(ctx, nil, err)
+	}
+
+	// 注册用户中间件和服务，路由冲突时停止已启动的服务并终止启动
 	e.registerUserMiddlewares()
-	e.ginSvr.Use(mid.AccessLogger(e.config.logger, e.config.accessLogEventFunc, e.opts.recReqBody))
-	e.registerUserServices()
+	e.ginSvr.Use(mid.AccessLoggerWithFilter(e.config.logger, e.config.accessLogEventFunc, e.opts.recReqBody, e.accessLogFilter))
+	if e.config.HttpRequestTimeout > 0 {
+		e.ginSvr.Use(umid.Timeout(time.Duration(e.config.HttpRequestTimeout) * time.Millisecond))
+	}
+	err = e.registerUserServices()
+	e.routesApplied = true
+	if err != nil {
+		return e.abortStart(ctx, started, err)
+	}
+
+	// 创建监听器，绑定失败时停止已启动的服务并终止启动
+	if err := e.createListeners(); err != nil {
+		return e.abortStart(ctx, started, err)
+	}
+	e.started = started
+
+	e.draining.Store(false)
+
+	// 每次运行使用独立的上下文，每个 HTTP 服务器最多上报一次运行错误
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+	e.serveErrCh = make(chan error, 2)
+	e.setRunError(nil)
 
 	// 创建并启动 HTTP 服务器
-	e.httpSvr = e.createHTTPServer()
+	e.httpSvr = e.createHTTPServer(e.endpoint, e.ginSvr, e.tlsConfig)
 	e.wg.Add(1)
+	go e.startHTTPServer(e.httpSvr, e.listener, httpServerName)
+
+	// 创建并启动管理端 HTTP 服务器
+	if e.adminListener != nil {
+		e.adminHttpSvr = e.createHTTPServer(e.adminEndpoint, e.adminSvr, nil)
+		e.wg.Add(1)
+		go e.startHTTPServer(e.adminHttpSvr, e.adminListener, adminServerName)
+	}
 
-	go e.startHTTPServer()
+	// 启动证书热加载
+	e.startCertWatcher()
 
-	// 更新服务器状态
-	e.updateRunningState(true)
+	// 启动信号处理
+	e.startSignalHandler()
+
+	// 更新服务器状态并通知等待者
+	e.setState(EngineStateRunning)
+	close(e.ready)
+
+	// 如果当前进程由升级启动，通知父进程已就绪
+	listener.NotifyReady()
+	return nil
+}
+
+// 启动失败时停止已启动的服务并注销度量标准，下一次启动时重新构建路由并注册度量标准
+func (e *Engine) abortStart(ctx context.Context, started []Service, err error) error {
+	err = errors.Join(err, e.runStopHooks(ctx, started))
+	e.unregisterMetrics()
+	e.routesApplied = true
+	e.setRunError(err)
+	return err
+}
+
+// 启用指标收集时，从注册表中注销度量标准
+func (e *Engine) unregisterMetrics() {
+	if e.opts.metric {
+		e.metric.Unregister()
+		e.healthMetric.Unregister()
+	}
 }
 
 // 创建并配置 HTTP 服务器实例
-func (e *Engine) createHTTPServer() *http.Server {
+func (e *Engine) createHTTPServer(endpoint string, handler http.Handler, tlsConfig *tls.Config) *http.Server {
 	// 使用合理的 MaxHeaderBytes 值
 	maxHeaderBytes := com.DefaultMaxHeaderBytes
 	if e.config.MaxHeaderBytes > 0 {
@@ -202,94 +472,208 @@ func (e *Engine) createHTTPServer() *http.Server {
 	}
 
 	return &http.Server{
-		Addr:              e.endpoint,                                                       // 服务器监听地址
-		Handler:           e.ginSvr,                                                         // Gin 引擎处理器
+		Addr:              endpoint,                                                         // 服务器监听地址
+		Handler:           handler,                                                          // Gin 引擎处理器
 		ReadTimeout:       time.Duration(e.config.HttpReadTimeout) * time.Millisecond,       // 读取超时时间
 		ReadHeaderTimeout: time.Duration(e.config.HttpReadHeaderTimeout) * time.Millisecond, // 读取头部超时时间
 		WriteTimeout:      time.Duration(e.config.HttpWriteTimeout) * time.Millisecond,      // 写入超时时间
 		IdleTimeout:       idleTimeout,                                                      // 空闲超时时间
 		MaxHeaderBytes:    maxHeaderBytes,                                                   // 最大头部字节数
 		ErrorLog:          ilog.NewStandardLoggerFromLogr(e.config.logger),                  // 错误日志记录器
+		TLSConfig:         tlsConfig,                                                        // TLS 配置（nil 表示使用 HTTP）
 	}
 }
 
-// 启动 HTTP 服务器并处理可能的错误
-func (e *Engine) startHTTPServer() {
+// 在监听器上启动 HTTP 服务器并处理可能的错误
+func (e *Engine) startHTTPServer(srv *http.Server, l net.Listener, name string) {
 	defer e.wg.Done()
-	e.httpSvr.SetKeepAlivesEnabled(true)
-	e.config.logger.Info("http server is ready", "address", e.endpoint)
-	if err := e.httpSvr.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		e.config.logger.Error(err, "failed to start http server", "address", e.endpoint)
-		e.runErrMu.Lock()
-		e.runErr = err
-		e.runErrMu.Unlock()
+	endpoint := listenerAddress(l)
+	srv.SetKeepAlivesEnabled(true)
+	e.config.logger.Info("http server is ready", "server", name, "address", endpoint, "tls", srv.TLSConfig != nil)
+
+	var err error
+	if srv.TLSConfig != nil {
+		// 证书由 TLSConfig.GetCertificate 动态提供，无需传入证书文件
+		err = srv.ServeTLS(l, "", "")
+	} else {
+		err = srv.Serve(l)
+	}
+	if err != nil && err != http.ErrServerClosed {
+		e.config.logger.Error(err, "failed to start http server", "server", name, "address", endpoint)
+		e.setRunError(err)
+		e.serveErrCh <- fmt.Errorf("%s server on %s: %w", name, endpoint, err)
 	}
 }
 
-// 优雅地停止 HTTP 服务器
+// 记录服务器运行过程中的错误
+func (e *Engine) setRunError(err error) {
+	e.runErrMu.Lock()
+	e.runErr = err
+	e.runErrMu.Unlock()
+}
+
+// 优雅地停止 HTTP 服务器，关闭前的等待时间和关闭超时时间由配置决定
+// 引擎未运行时直接返回，停止后可以再次调用 Run 启动
 func (e *Engine) Stop() {
-	e.once.Do(func() {
-		// 更新服务器状态为停止
-		e.updateRunningState(false)
+	_ = e.Shutdown(context.Background())
+}
 
-		shutdownCtx, shutdownCancel := context.WithTimeout(e.ctx, defaultShutdownTimeout)
-		defer shutdownCancel()
+// Shutdown 按以下顺序停止 HTTP 服务器：
+//  1. 将引擎标记为未就绪，健康检查返回 503
+//  2. 等待 PreStopDelay，让负载均衡摘除流量
+//  3. 在 ShutdownTimeout 内等待进行中的请求处理完成
+//  4. 超时后强制关闭剩余连接，并记录被中断的请求数量
+//
+// ctx 可以进一步缩短整个过程。引擎未运行时返回 ErrEngineNotRunning；其他调用正在停止引擎时，等待其完成后返回
+func (e *Engine) Shutdown(ctx context.Context) error {
+	e.stateMu.Lock()
+	switch e.State() {
+	case EngineStateRunning:
+	case EngineStateStopping:
+		stopped := e.stopped
+		e.stateMu.Unlock()
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	default:
+		e.stateMu.Unlock()
+		return ErrEngineNotRunning
+	}
+	stopped := make(chan struct{})
+	e.stopped = stopped
+	e.setState(EngineStateStopping)
+	e.stateMu.Unlock()
+
+	// 通知服务即将停止
+	hookErr := e.runBeforeShutdownHooks(ctx, e.started)
+
+	// 标记为未就绪，并关闭长连接，促使客户端重新建立连接到其他实例
+	e.draining.Store(true)
+	e.httpSvr.SetKeepAlivesEnabled(false)
+	e.waitPreStopDelay(ctx)
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, time.Duration(e.config.ShutdownTimeout)*time.Millisecond)
+	defer shutdownCancel()
+
+	// 关闭 HTTP 服务器
+	err := errors.Join(
+		hookErr,
+		e.shutdownHTTPServer(shutdownCtx, e.httpSvr, httpServerName, e.GetListenEndpoint(), &e.inflight),
+		e.shutdownHTTPServer(shutdownCtx, e.adminHttpSvr, adminServerName, e.GetAdminEndpoint(), &e.adminInflight),
+	)
 
-		// 关闭 HTTP 服务器
-		e.shutdownHTTPServer(shutdownCtx)
+	// 取消上下文并等待所有协程完成
+	e.cancel()
+	e.wg.Wait()
 
-		// 取消上下文并等待所有协程完成
-		e.cancel()
-		e.wg.Wait()
+	// 按相反顺序调用服务的停止钩子，释放服务持有的资源
+	err = errors.Join(err, e.runStopHooks(ctx, e.started))
+	e.started = nil
 
-		// 如果启用了指标收集，注销指标收集器
-		if e.opts.metric {
-			e.metric.Unregister()
-		}
-	})
+	// 如果启用了指标收集，注销指标收集器
+	e.unregisterMetrics()
+
+	// 更新服务器状态为停止，并为下一次启动准备就绪通道
+	e.stateMu.Lock()
+	e.setState(EngineStateStopped)
+	e.ready = make(chan struct{})
+	close(stopped)
+	e.stateMu.Unlock()
+	return err
 }
 
-// 优雅地关闭 HTTP 服务器
-func (e *Engine) shutdownHTTPServer(ctx context.Context) {
-	if e.httpSvr == nil {
+// 等待关闭前的延迟时间，ctx 结束时提前返回
+func (e *Engine) waitPreStopDelay(ctx context.Context) {
+	if e.config.PreStopDelay == 0 {
 		return
 	}
-	if err := e.httpSvr.Shutdown(ctx); err != nil {
-		e.config.logger.Error(err, "http server forced to shutdown", "address", e.endpoint)
+
+	delay := time.Duration(e.config.PreStopDelay) * time.Millisecond
+	e.config.logger.Info("engine is draining, waiting before shutdown", "address", e.GetListenEndpoint(), "delay", delay.String())
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
 	}
-	e.config.logger.Info("http server is shutdown", "address", e.endpoint)
 }
 
-// 返回服务器的运行状态
-func (e *Engine) IsRunning() bool {
-	return e.running.Load()
+// 优雅地关闭 HTTP 服务器，超时后强制关闭剩余连接
+func (e *Engine) shutdownHTTPServer(ctx context.Context, srv *http.Server, name, endpoint string, inflight *atomic.Int64) error {
+	if srv == nil {
+		return nil
+	}
+	err := srv.Shutdown(ctx)
+	if err != nil {
+		e.config.logger.Error(err, "http server shutdown timed out, in-flight requests are cut off", "server", name, "address", endpoint, "inflight", inflight.Load())
+		_ = srv.Close()
+		err = fmt.Errorf("failed to shutdown %s server on %s: %w", name, endpoint, err)
+	}
+	e.config.logger.Info("http server is shutdown", "server", name, "address", endpoint)
+	return err
 }
 
-// 更新服务器的运行状态
-func (e *Engine) updateRunningState(status bool) {
-	e.running.Store(status)
+// 返回引擎是否正在排空流量，排空期间健康检查返回 503
+func (e *Engine) IsDraining() bool {
+	return e.draining.Load()
 }
 
 // 注册用户定义的服务
-func (e *Engine) registerUserServices() {
+func (e *Engine) registerUserServices() error {
 	// 只在服务器未运行时注册服务
-	if !e.running.Load() {
-		for i := 0; i < len(e.services); i++ {
-			e.services[i].RegisterGroup(e.root)
+	if e.IsRunning() {
+		return nil
+	}
+	for i := 0; i < len(e.services); i++ {
+		if err := e.registerServiceRoutes(e.services[i]); err != nil {
+			return err
 		}
 	}
+	return nil
+}
+
+// 为服务创建路由组，返回路由组和用于路由表的服务名称
+// 服务声明了名称、前缀或中间件时，以前缀创建子路由组，并依次添加服务标记中间件和服务中间件
+func (e *Engine) serviceGroup(service Service) (*gin.RouterGroup, string) {
+	var name, basePath string
+	var handlers []gin.HandlerFunc
+	if named, ok := service.(NamedService); ok {
+		name = named.Name()
+	}
+	if based, ok := service.(BasePathService); ok {
+		basePath = based.BasePath()
+	}
+	if name != "" {
+		handlers = append(handlers, mid.ServiceName(name))
+	}
+	if scoped, ok := service.(MiddlewareService); ok {
+		handlers = append(handlers, scoped.Middlewares()...)
+	}
+
+	owner := name
+	if owner == "" {
+		owner = fmt.Sprintf("%T", service)
+	}
+	if basePath == "" && len(handlers) == 0 {
+		return e.root, owner
+	}
+	return e.root.Group(basePath, handlers...), owner
 }
 
 // 添加用户定义的服务到服务列表中
 func (e *Engine) RegisterService(service Service) {
-	if !e.running.Load() && service != nil {
+	if !e.IsRunning() && service != nil {
 		e.services = append(e.services, service)
 	}
 }
 
 // 添加中间件到处理器列表中
 func (e *Engine) RegisterMiddleware(handler gin.HandlerFunc) {
-	if !e.running.Load() && handler != nil {
+	if !e.IsRunning() && handler != nil {
 		e.handlers = append(e.handlers, handler)
 	}
 }
@@ -297,7 +681,7 @@ func (e *Engine) RegisterMiddleware(handler gin.HandlerFunc) {
 // 注册用户定义的中间件
 func (e *Engine) registerUserMiddlewares() {
 	// 只在服务器未运行时注册中间件
-	if !e.running.Load() {
+	if !e.IsRunning() {
 		e.ginSvr.Use(e.handlers...)
 	}
 }
@@ -323,16 +707,31 @@ func (e *Engine) GetPrometheusRegistry() *prometheus.Registry {
 }
 
 // 返回服务器的监听地址
+// 服务器启动后返回实际绑定的地址（包括端口为 0 时系统分配的端口），否则返回配置的地址
 func (e *Engine) GetListenEndpoint() string {
+	if e.listener != nil {
+		return listenerAddress(e.listener)
+	}
 	return e.endpoint
 }
 
+// 返回管理端的监听地址，未启用管理端时返回空字符串
+func (e *Engine) GetAdminEndpoint() string {
+	if e.adminListener != nil {
+		return listenerAddress(e.adminListener)
+	}
+	return e.adminEndpoint
+}
+
+// 返回服务器启动或运行过程中最近一次发生的错误
 func (e *Engine) GetRunError() error {
 	e.runErrMu.Lock()
 	defer e.runErrMu.Unlock()
 	return e.runErr
 }
 
+// 返回业务端的 Gin 引擎
+// 引擎重启时会重新构建 Gin 引擎，直接注册在旧引擎上的路由不会保留
 func (e *Engine) GetGinEngine() *gin.Engine {
 	return e.ginSvr
 }
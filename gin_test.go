@@ -1,13 +1,20 @@
 package orbit
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	com "github.com/shengyanli1982/orbit/common"
+	umid "github.com/shengyanli1982/orbit/utils/middleware"
+	"github.com/shengyanli1982/orbit/utils/requestid"
+	"github.com/shengyanli1982/orbit/utils/tracing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type emptyBodyService struct{}
@@ -16,6 +23,23 @@ func (s *emptyBodyService) RegisterGroup(g *gin.RouterGroup) {
 	g.GET("/empty", func(ctx *gin.Context) {})
 }
 
+// 声明了名称、前缀和中间件的服务
+type scopedService struct{}
+
+func (s *scopedService) Name() string     { return "users" }
+func (s *scopedService) BasePath() string { return "/v1/users" }
+func (s *scopedService) Middlewares() []gin.HandlerFunc {
+	return []gin.HandlerFunc{func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+	}}
+}
+
+func (s *scopedService) RegisterGroup(g *gin.RouterGroup) {
+	g.GET("/:id", func(c *gin.Context) { c.String(http.StatusOK, c.Param("id")) })
+}
+
 type clientIPService struct{}
 
 func (s *clientIPService) RegisterGroup(g *gin.RouterGroup) {
@@ -413,3 +437,386 @@ func TestRunNotFailWhenForwardedDisabledEvenIfTrustedProxiesInvalid(t *testing.T
 
 	assert.True(t, engine.IsRunning())
 }
+
+func TestRunContextReturnsInitError(t *testing.T) {
+	config := NewConfig().WithTrustedProxies([]string{"invalid-cidr"})
+	engine := NewEngine(config, NewOptions().EnableForwardedByClientIp())
+
+	err := engine.RunContext(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, engine.initErr, err)
+	assert.False(t, engine.IsRunning())
+}
+
+func TestRunContextBlocksUntilContextCancelled(t *testing.T) {
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), NewOptions())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() { done <- engine.RunContext(ctx) }()
+
+	select {
+	case <-engine.Ready():
+	case <-time.After(5 * time.Second):
+		t.Fatal("engine not ready")
+	}
+	assert.True(t, engine.IsRunning())
+	assert.Equal(t, com.RequestOK, getHealthCheck(t, http.DefaultClient, "http://"+engine.GetListenEndpoint()))
+
+	// 重复启动返回错误
+	assert.ErrorIs(t, engine.RunContext(context.Background()), ErrEngineRunning)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunContext did not return after context cancelled")
+	}
+	assert.False(t, engine.IsRunning())
+}
+
+func TestRunContextReturnsWhenStopped(t *testing.T) {
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), NewOptions())
+
+	done := make(chan error, 1)
+	go func() { done <- engine.RunContext(context.Background()) }()
+	<-engine.Ready()
+
+	engine.Stop()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunContext did not return after engine stopped")
+	}
+}
+
+func TestRunContextReturnsServeError(t *testing.T) {
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), NewOptions())
+
+	done := make(chan error, 1)
+	go func() { done <- engine.RunContext(context.Background()) }()
+	<-engine.Ready()
+
+	// 关闭底层监听器，模拟服务器运行失败
+	require.NoError(t, engine.listener.Close())
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunContext did not return after server failed")
+	}
+	assert.False(t, engine.IsRunning())
+}
+
+func TestEnginesShareRegistryRequireServerName(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	options := NewOptions().EnableMetric()
+
+	first := NewEngine(NewConfig().WithPrometheusRegistry(registry), options)
+	require.NoError(t, first.initErr)
+
+	// 未区分引擎时，度量标准冲突作为启动错误返回而不是 panic
+	second := NewEngine(NewConfig().WithPrometheusRegistry(registry), options)
+	assert.ErrorContains(t, second.initErr, "failed to register metric collector")
+	assert.Error(t, second.RunContext(context.Background()))
+}
+
+func TestEnginesShareRegistryWithServerName(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	options := NewOptions().EnableMetric()
+	newConfig := func(name string) *Config {
+		return NewConfig().WithAddress("127.0.0.1").WithEphemeralPort().WithPrometheusRegistry(registry).WithServerName(name)
+	}
+
+	group := NewEngineGroup(NewEngine(newConfig("public"), options), NewEngine(newConfig("internal"), options))
+	for _, engine := range group.Engines() {
+		require.NoError(t, engine.initErr)
+	}
+	require.NoError(t, group.Start(context.Background()))
+	defer func() { _ = group.Shutdown(context.Background()) }()
+
+	for _, engine := range group.Engines() {
+		resp, err := http.Get("http://" + engine.GetListenEndpoint() + "/not-found")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	servers := make(map[string]struct{})
+	for _, family := range families {
+		if family.GetName() != "orbit_http_requests_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "server" {
+					servers[label.GetValue()] = struct{}{}
+				}
+			}
+		}
+	}
+	assert.Contains(t, servers, "public")
+	assert.Contains(t, servers, "internal")
+
+	// 重启后重新注册度量标准不会冲突
+	require.NoError(t, group.Shutdown(context.Background()))
+	require.NoError(t, group.Start(context.Background()))
+}
+
+func TestScopedService(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	logger, buf := newBufferZapLogger()
+	config := NewConfig().WithAddress("127.0.0.1").WithEphemeralPort().WithPrometheusRegistry(registry).WithZapLogger(logger)
+	engine := NewEngine(config, NewOptions().EnableMetric())
+	engine.RegisterService(&scopedService{})
+	engine.RegisterService(&emptyBodyService{})
+	engine.Run()
+	defer engine.Stop()
+
+	request := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != nil {
+			req.Header = header
+		}
+		recorder := httptest.NewRecorder()
+		engine.GetGinEngine().ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// 服务中间件只作用于该服务的路由
+	assert.Equal(t, http.StatusUnauthorized, request("/v1/users/42", nil).Code)
+	recorder := request("/v1/users/42", http.Header{"Authorization": []string{"token"}})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "42", recorder.Body.String())
+	assert.Equal(t, http.StatusOK, request("/empty", nil).Code)
+
+	// 访问日志和度量标准带有服务名称
+	assert.Contains(t, buf.String(), `"service":"users"`)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	services := make(map[string]string)
+	for _, family := range families {
+		if family.GetName() != "orbit_http_requests_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			var path, service string
+			for _, label := range m.GetLabel() {
+				switch label.GetName() {
+				case "path":
+					path = label.GetValue()
+				case "service":
+					service = label.GetValue()
+				}
+			}
+			services[path] = service
+		}
+	}
+	assert.Equal(t, "users", services["/v1/users/:id"])
+	assert.Equal(t, "", services["/empty"])
+
+	// 路由表中记录服务名称和服务中间件
+	route, ok := findRoute(engine.Routes(), http.MethodGet, "/v1/users/:id")
+	require.True(t, ok)
+	assert.Equal(t, "users", route.Service)
+	assert.Contains(t, route.Middlewares[len(route.Middlewares)-2], "ServiceName")
+}
+
+// 处理时间超过请求超时时间的服务
+type slowService struct{}
+
+func (s *slowService) RegisterGroup(g *gin.RouterGroup) {
+	g.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.String(http.StatusOK, "late")
+	})
+	g.GET("/fast", func(c *gin.Context) { c.String(http.StatusOK, "fast") })
+}
+
+func TestEngineRequestTimeout(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	logger, buf := newBufferZapLogger()
+	config := NewConfig().WithAddress("127.0.0.1").WithEphemeralPort().WithPrometheusRegistry(registry).WithZapLogger(logger).WithHttpRequestTimeout(20)
+	engine := NewEngine(config, NewOptions().EnableMetric())
+	engine.RegisterService(&slowService{})
+	engine.Run()
+	defer engine.Stop()
+
+	request := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		engine.GetGinEngine().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	recorder := request("/slow")
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.JSONEq(t, `{"code":10,"message":"request timeout"}`, recorder.Body.String())
+	assert.Equal(t, "fast", request("/fast").Body.String())
+
+	// 访问日志记录超时响应的状态码
+	assert.Contains(t, buf.String(), `"code":503`)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var timeouts float64
+	for _, family := range families {
+		if family.GetName() == "orbit_http_request_timeouts_total" {
+			for _, m := range family.GetMetric() {
+				timeouts += m.GetCounter().GetValue()
+			}
+		}
+	}
+	assert.Equal(t, 1.0, timeouts)
+}
+
+func TestEngineRateLimitUsesResolvedClientIP(t *testing.T) {
+	engine := NewEngine(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort(), NewOptions().EnableForwardedByClientIp())
+	engine.RegisterMiddleware(umid.RateLimit(umid.RateLimitConfig{Rate: 1, Burst: 1}))
+	engine.RegisterService(&clientIPService{})
+	engine.Run()
+	defer engine.Stop()
+
+	request := func(forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/client-ip", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set(com.HttpHeaderForwardedFor, forwardedFor)
+		recorder := httptest.NewRecorder()
+		engine.GetGinEngine().ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("1.1.1.1"))
+	assert.Equal(t, http.StatusTooManyRequests, request("1.1.1.1"))
+	assert.Equal(t, http.StatusOK, request("2.2.2.2"))
+}
+
+func TestEngineRequestID(t *testing.T) {
+	logger, buf := newBufferZapLogger()
+	config := NewConfig().WithAddress("127.0.0.1").WithEphemeralPort().WithZapLogger(logger).WithRequestIDGenerator(requestid.NewULID)
+	engine := NewEngine(config, NewOptions().EnableRequestID())
+	engine.RegisterService(&emptyBodyService{})
+	engine.Run()
+	defer engine.Stop()
+
+	recorder := httptest.NewRecorder()
+	engine.GetGinEngine().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/empty", nil))
+
+	id := recorder.Header().Get(com.HttpHeaderRequestID)
+	assert.Len(t, id, 26)
+	assert.Contains(t, buf.String(), `"id":"`+id+`"`)
+}
+
+func TestEngineRequestIDDisabledByDefault(t *testing.T) {
+	engine := NewEngine(NewConfig(), NewOptions())
+	recorder := httptest.NewRecorder()
+	engine.GetGinEngine().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, com.HealthCheckURLPath, nil))
+	assert.Empty(t, recorder.Header().Get(com.HttpHeaderRequestID))
+}
+
+func TestEngineTracing(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	logger, buf := newBufferZapLogger()
+	exporter := tracing.NewMemoryExporter()
+	config := NewConfig().WithAddress("127.0.0.1").WithEphemeralPort().WithPrometheusRegistry(registry).WithZapLogger(logger).
+		WithTracer(tracing.NewTracer("orbit-test", exporter))
+	engine := NewEngine(config, NewOptions().EnableMetric())
+	engine.RegisterService(&emptyBodyService{})
+	engine.Run()
+	defer engine.Stop()
+
+	req := httptest.NewRequest(http.MethodGet, "/empty", nil)
+	req.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	engine.GetGinEngine().ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].TraceID)
+	assert.Equal(t, "GET /empty", spans[0].Name)
+	assert.Contains(t, buf.String(), `"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+	assert.Contains(t, buf.String(), `"spanId":"`+spans[0].SpanID+`"`)
+
+	// 请求计数和耗时分布带有链路 ID 的 exemplar
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var exemplars int
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			if e := m.GetCounter().GetExemplar(); e != nil {
+				assert.Equal(t, "trace_id", e.GetLabel()[0].GetName())
+				assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", e.GetLabel()[0].GetValue())
+				exemplars++
+			}
+			for _, bucket := range m.GetHistogram().GetBucket() {
+				if bucket.GetExemplar() != nil {
+					exemplars++
+				}
+			}
+		}
+	}
+	assert.Equal(t, 2, exemplars)
+}
+
+func TestEngineTracingUnsampledSkipsExemplar(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	config := NewConfig().WithAddress("127.0.0.1").WithEphemeralPort().WithPrometheusRegistry(registry).
+		WithTracer(tracing.NewTracer("orbit-test", tracing.NewMemoryExporter()).WithSampleRatio(0))
+	engine := NewEngine(config, NewOptions().EnableMetric())
+	engine.RegisterService(&emptyBodyService{})
+	engine.Run()
+	defer engine.Stop()
+
+	engine.GetGinEngine().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/empty", nil))
+
+	// 未采样的链路不会被导出，请求计数和耗时分布不带 exemplar
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var requests int
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			if m.GetCounter() != nil {
+				requests++
+				assert.Nil(t, m.GetCounter().GetExemplar())
+			}
+			for _, bucket := range m.GetHistogram().GetBucket() {
+				assert.Nil(t, bucket.GetExemplar())
+			}
+		}
+	}
+	assert.NotZero(t, requests)
+}
+
+func TestEngineAccessLogPolicyCountsDroppedLogs(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	logger, buf := newBufferZapLogger()
+	config := NewConfig().WithAddress("127.0.0.1").WithEphemeralPort().WithPrometheusRegistry(registry).WithZapLogger(logger).
+		WithAccessLogPolicy(com.AccessLogPolicy{RouteSampleRates: map[string]float64{"/empty": 0}, SkipMethods: []string{http.MethodHead}})
+	engine := NewEngine(config, NewOptions().EnableMetric())
+	engine.RegisterService(&emptyBodyService{})
+	engine.Run()
+	defer engine.Stop()
+
+	for _, method := range []string{http.MethodGet, http.MethodGet, http.MethodHead} {
+		engine.GetGinEngine().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/empty", nil))
+	}
+	assert.NotContains(t, buf.String(), "http server access log")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	dropped := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() == "orbit_http_access_logs_dropped_total" {
+			for _, m := range family.GetMetric() {
+				for _, label := range m.GetLabel() {
+					if label.GetName() == "reason" {
+						dropped[label.GetValue()] += m.GetCounter().GetValue()
+					}
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{com.AccessLogSampledOut: 2, com.AccessLogFiltered: 1}, dropped)
+}
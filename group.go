@@ -0,0 +1,117 @@
+package orbit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EngineGroup 在同一进程中协调运行多个引擎
+// 任一引擎启动失败时停止已启动的引擎；任一引擎退出时停止所有引擎；所有引擎在同一个截止时间内并行停止
+type EngineGroup struct {
+	engines         []*Engine
+	shutdownTimeout uint32
+}
+
+// NewEngineGroup 创建引擎组，引擎按传入顺序启动
+func NewEngineGroup(engines ...*Engine) *EngineGroup {
+	return &EngineGroup{engines: engines}
+}
+
+// WithShutdownTimeout 设置所有引擎共享的停止超时时间（毫秒）
+// 为 0 时使用各引擎中 PreStopDelay 与 ShutdownTimeout 之和的最大值
+func (g *EngineGroup) WithShutdownTimeout(timeout uint32) *EngineGroup {
+	g.shutdownTimeout = timeout
+	return g
+}
+
+// Engines 返回组内的引擎
+func (g *EngineGroup) Engines() []*Engine {
+	return g.engines
+}
+
+// Start 按顺序启动所有引擎，任一引擎启动失败（例如无法绑定地址）时，并行停止已启动的引擎并返回错误
+func (g *EngineGroup) Start(ctx context.Context) error {
+	for i, engine := range g.engines {
+		if err := engine.start(ctx); err != nil {
+			err = fmt.Errorf("failed to start engine %d (%s): %w", i, engine.GetListenEndpoint(), err)
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), g.timeout())
+			defer cancel()
+			return errors.Join(err, shutdownEngines(shutdownCtx, g.engines[:i]))
+		}
+	}
+	return nil
+}
+
+// RunContext 启动所有引擎并阻塞，直到 ctx 被取消或任一引擎退出，然后在共享的截止时间内并行停止所有引擎
+// 启动失败时直接返回错误；返回值包含引擎运行失败与停止失败的错误
+func (g *EngineGroup) RunContext(ctx context.Context) error {
+	if err := g.Start(ctx); err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, len(g.engines))
+	var wg sync.WaitGroup
+	for i, engine := range g.engines {
+		wg.Add(1)
+		go func(i int, engine *Engine) {
+			defer wg.Done()
+			// 任一引擎退出时通知其他引擎停止
+			defer cancel()
+
+			err := engine.wait(runCtx)
+			if runCtx.Err() == nil {
+				engine.config.logger.Info("engine exited, stopping engine group", "address", engine.GetListenEndpoint())
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("engine %d (%s): %w", i, engine.GetListenEndpoint(), err)
+			}
+		}(i, engine)
+	}
+	wg.Wait()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), g.timeout())
+	defer shutdownCancel()
+	return errors.Join(errors.Join(errs...), g.Shutdown(shutdownCtx))
+}
+
+// Shutdown 并行停止组内所有运行中的引擎，ctx 为所有引擎共享的截止时间
+func (g *EngineGroup) Shutdown(ctx context.Context) error {
+	return shutdownEngines(ctx, g.engines)
+}
+
+// 返回共享的停止超时时间
+func (g *EngineGroup) timeout() time.Duration {
+	timeout := g.shutdownTimeout
+	if timeout == 0 {
+		for _, engine := range g.engines {
+			if t := engine.config.PreStopDelay + engine.config.ShutdownTimeout; t > timeout {
+				timeout = t
+			}
+		}
+	}
+	return time.Duration(timeout) * time.Millisecond
+}
+
+// 并行停止引擎，忽略未运行的引擎
+func shutdownEngines(ctx context.Context, engines []*Engine) error {
+	errs := make([]error, len(engines))
+	var wg sync.WaitGroup
+	for i, engine := range engines {
+		wg.Add(1)
+		go func(i int, engine *Engine) {
+			defer wg.Done()
+			if err := engine.Shutdown(ctx); err != nil && !errors.Is(err, ErrEngineNotRunning) {
+				errs[i] = fmt.Errorf("failed to stop engine %d (%s): %w", i, engine.GetListenEndpoint(), err)
+			}
+		}(i, engine)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
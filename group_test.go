@@ -0,0 +1,112 @@
+package orbit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 创建监听随机端口的引擎
+func newGroupTestEngine() *Engine {
+	return NewEngine(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort(), NewOptions())
+}
+
+func TestEngineGroupStartAndShutdown(t *testing.T) {
+	group := NewEngineGroup(newGroupTestEngine(), newGroupTestEngine())
+	require.NoError(t, group.Start(context.Background()))
+
+	for _, engine := range group.Engines() {
+		assert.True(t, engine.IsRunning())
+	}
+
+	assert.NoError(t, group.Shutdown(context.Background()))
+	for _, engine := range group.Engines() {
+		assert.Equal(t, EngineStateStopped, engine.State())
+	}
+
+	// 已停止的引擎不会导致错误
+	assert.NoError(t, group.Shutdown(context.Background()))
+}
+
+func TestEngineGroupStartFailsFast(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer occupied.Close()
+	port := uint16(occupied.Addr().(*net.TCPAddr).Port)
+
+	first := newGroupTestEngine()
+	second := NewEngine(NewConfig().WithAddress("127.0.0.1").WithPort(port), NewOptions())
+	third := newGroupTestEngine()
+
+	err = NewEngineGroup(first, second, third).Start(context.Background())
+	assert.ErrorContains(t, err, "failed to start engine 1")
+
+	assert.Equal(t, EngineStateStopped, first.State())
+	assert.Equal(t, EngineStateNew, second.State())
+	assert.Equal(t, EngineStateNew, third.State())
+}
+
+func TestEngineGroupStopsAllWhenOneExits(t *testing.T) {
+	group := NewEngineGroup(newGroupTestEngine(), newGroupTestEngine(), newGroupTestEngine())
+
+	done := make(chan error, 1)
+	go func() { done <- group.RunContext(context.Background()) }()
+
+	for _, engine := range group.Engines() {
+		select {
+		case <-engine.Ready():
+		case <-time.After(5 * time.Second):
+			t.Fatal("engine did not become ready")
+		}
+	}
+
+	group.Engines()[1].Stop()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("engine group did not stop")
+	}
+	for _, engine := range group.Engines() {
+		assert.Equal(t, EngineStateStopped, engine.State())
+	}
+}
+
+func TestEngineGroupRunContextCancel(t *testing.T) {
+	group := NewEngineGroup(newGroupTestEngine(), newGroupTestEngine())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- group.RunContext(ctx) }()
+
+	for _, engine := range group.Engines() {
+		<-engine.Ready()
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("engine group did not stop")
+	}
+	for _, engine := range group.Engines() {
+		assert.False(t, engine.IsRunning())
+	}
+}
+
+func TestEngineGroupShutdownTimeout(t *testing.T) {
+	first := NewEngine(NewConfig().WithShutdownTimeout(3000).WithPreStopDelay(500), NewOptions())
+	second := NewEngine(NewConfig().WithShutdownTimeout(2000), NewOptions())
+
+	group := NewEngineGroup(first, second)
+	assert.Equal(t, 3500*time.Millisecond, group.timeout())
+
+	group.WithShutdownTimeout(1000)
+	assert.Equal(t, time.Second, group.timeout())
+}
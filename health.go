@@ -0,0 +1,84 @@
+package orbit
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/shengyanli1982/orbit/internal/conver"
+	"github.com/shengyanli1982/orbit/internal/health"
+)
+
+// 健康探针对应的路由路径
+var probeURLPaths = map[com.HealthProbe]string{
+	com.HealthProbeLiveness:  com.LivezURLPath,
+	com.HealthProbeReadiness: com.ReadyzURLPath,
+	com.HealthProbeStartup:   com.StartupzURLPath,
+}
+
+// 初始化存活、就绪和启动探针
+func (e *Engine) initHealthProbes() {
+	ttl := time.Duration(e.config.HealthCheckCacheTTL) * time.Millisecond
+	e.probers = make(map[com.HealthProbe]*health.Prober, len(probeURLPaths))
+	for probe := range probeURLPaths {
+		e.probers[probe] = health.NewProber(probe, ttl, e.healthMetric)
+	}
+}
+
+// 注册健康检查项到指定的探针，同一探针中同名的检查项会被替换
+// 探针类型未知、名称为空或检查函数为 nil 时忽略
+func (e *Engine) RegisterHealthChecker(probe com.HealthProbe, checker com.HealthChecker) {
+	prober, ok := e.probers[probe]
+	if !ok || checker.Name == "" || checker.Check == nil {
+		return
+	}
+	prober.Register(checker)
+}
+
+// 注册存活、就绪和启动探针路由
+func (e *Engine) registerProbeServices(root *gin.RouterGroup) {
+	for probe, path := range probeURLPaths {
+		root.GET(path, e.probeHandler(probe))
+	}
+}
+
+// 返回探针处理函数
+// 探针通过时返回 200，否则返回 503；请求带有 verbose 参数时返回 JSON 格式的检查详情
+func (e *Engine) probeHandler(probe com.HealthProbe) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := e.probeReport(probe)
+
+		status := http.StatusOK
+		if !report.IsHealthy() {
+			status = http.StatusServiceUnavailable
+		}
+		if _, verbose := c.GetQuery("verbose"); verbose {
+			c.JSON(status, report)
+			return
+		}
+		c.Data(status, "text/plain; charset=utf-8", conver.StringToBytes(report.Status))
+	}
+}
+
+// 执行探针并返回结果
+// 引擎排空流量时就绪探针直接失败，引擎未完成启动时就绪和启动探针直接失败
+func (e *Engine) probeReport(probe com.HealthProbe) *com.HealthReport {
+	switch probe {
+	case com.HealthProbeReadiness:
+		if e.IsDraining() {
+			return &com.HealthReport{Status: com.RequestDraining}
+		}
+		if !e.IsRunning() {
+			return &com.HealthReport{Status: com.HealthStatusStarting}
+		}
+	case com.HealthProbeStartup:
+		if e.State() == EngineStateNew {
+			return &com.HealthReport{Status: com.HealthStatusStarting}
+		}
+	}
+
+	// 检查结果会被缓存并在多个请求间共享，不使用请求的上下文
+	return e.probers[probe].Check(context.Background())
+}
@@ -0,0 +1,114 @@
+package orbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 请求指定的探针，返回状态码和响应体
+func getProbe(t *testing.T, endpoint, path string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get("http://" + endpoint + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestProbesBeforeStart(t *testing.T) {
+	engine := NewEngine(NewConfig().WithRelease(), NewOptions())
+
+	for path, code := range map[string]int{
+		com.LivezURLPath:    http.StatusOK,
+		com.ReadyzURLPath:   http.StatusServiceUnavailable,
+		com.StartupzURLPath: http.StatusServiceUnavailable,
+	} {
+		recorder := httptest.NewRecorder()
+		engine.ginSvr.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, code, recorder.Code, path)
+	}
+}
+
+func TestProbesWithHealthCheckers(t *testing.T) {
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), NewOptions())
+	dbErr := errors.New("connection refused")
+	engine.RegisterHealthChecker(com.HealthProbeReadiness, com.HealthChecker{
+		Name:     "db",
+		Critical: true,
+		Check:    func(context.Context) error { return dbErr },
+	})
+	engine.RegisterHealthChecker(com.HealthProbeReadiness, com.HealthChecker{
+		Name:  "cache",
+		Check: func(context.Context) error { return nil },
+	})
+	engine.RegisterHealthChecker(com.HealthProbeLiveness, com.HealthChecker{
+		Name:  "optional",
+		Check: func(context.Context) error { return errors.New("degraded") },
+	})
+	// 无效的检查项被忽略
+	engine.RegisterHealthChecker(com.HealthProbe("unknown"), com.HealthChecker{Name: "x", Check: func(context.Context) error { return nil }})
+	engine.RegisterHealthChecker(com.HealthProbeLiveness, com.HealthChecker{Name: "nil"})
+
+	engine.Run()
+	defer engine.Stop()
+	endpoint := engine.GetListenEndpoint()
+
+	code, body := getProbe(t, endpoint, com.LivezURLPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, com.HealthStatusOK, body)
+
+	code, body = getProbe(t, endpoint, com.StartupzURLPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, com.HealthStatusOK, body)
+
+	code, body = getProbe(t, endpoint, com.ReadyzURLPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, com.HealthStatusFailed, body)
+
+	code, body = getProbe(t, endpoint, com.ReadyzURLPath+"?verbose")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	var report com.HealthReport
+	require.NoError(t, json.Unmarshal([]byte(body), &report))
+	assert.Equal(t, com.HealthStatusFailed, report.Status)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "db", report.Checks[0].Name)
+	assert.True(t, report.Checks[0].Critical)
+	assert.Equal(t, dbErr.Error(), report.Checks[0].Error)
+	assert.Equal(t, com.HealthStatusOK, report.Checks[1].Status)
+
+	code, body = getProbe(t, endpoint, com.LivezURLPath+"?verbose=1")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, strings.Contains(body, `"optional"`))
+}
+
+func TestProbeMetricsExported(t *testing.T) {
+	config := NewConfig().WithRelease().WithEphemeralPort().WithPrometheusRegistry(prometheus.NewRegistry())
+	engine := NewEngine(config, ReleaseOptions())
+	engine.RegisterHealthChecker(com.HealthProbeReadiness, com.HealthChecker{
+		Name:  "db",
+		Check: func(context.Context) error { return nil },
+	})
+	engine.Run()
+	defer engine.Stop()
+	endpoint := engine.GetListenEndpoint()
+
+	code, _ := getProbe(t, endpoint, com.ReadyzURLPath)
+	require.Equal(t, http.StatusOK, code)
+
+	_, body := getProbe(t, endpoint, com.PromMetricURLPath)
+	assert.Contains(t, body, `orbit_health_check_status{check="db",probe="readyz"} 1`)
+	assert.Contains(t, body, `orbit_health_check_duration_seconds{check="db",probe="readyz"}`)
+}
@@ -0,0 +1,98 @@
+package orbit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// StartHook 可由 Service 实现，在服务器开始接收请求前调用
+// 返回错误时启动终止，已启动服务的 OnStop 会按相反顺序被调用
+// OnStart 执行期间引擎不持有内部锁，可以调用 Routes、Ready、ApplyConfig 等方法，再次启动引擎会返回 ErrEngineStarting
+type StartHook interface {
+	OnStart(ctx context.Context) error
+}
+
+// StopHook 可由 Service 实现，在 HTTP 服务器关闭后调用，用于释放服务持有的资源
+type StopHook interface {
+	OnStop(ctx context.Context) error
+}
+
+// BeforeShutdownHook 可由 Service 实现，在引擎开始排空流量前调用
+type BeforeShutdownHook interface {
+	BeforeShutdown(ctx context.Context) error
+}
+
+// HookNamer 可由 Service 实现，返回钩子在日志和错误中使用的名称
+// 适用于用函数适配器实现钩子的服务，未实现时使用服务名称（NamedService）或类型名称
+type HookNamer interface {
+	HookName() string
+}
+
+// 返回服务钩子在日志和错误中使用的名称
+func hookName(svc Service) string {
+	if named, ok := svc.(HookNamer); ok {
+		if name := named.HookName(); name != "" {
+			return name
+		}
+	}
+	if named, ok := svc.(NamedService); ok {
+		if name := named.Name(); name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", svc)
+}
+
+// 按注册顺序调用服务的 OnStart，返回成功启动的服务
+// 某个服务启动失败时，按相反顺序停止已启动的服务并返回错误
+func (e *Engine) runStartHooks(ctx context.Context, services []Service) ([]Service, error) {
+	started := make([]Service, 0, len(services))
+	for _, svc := range services {
+		if hook, ok := svc.(StartHook); ok {
+			if err := hook.OnStart(ctx); err != nil {
+				err = fmt.Errorf("service %s failed to start: %w", hookName(svc), err)
+				if stopErr := e.runStopHooks(ctx, started); stopErr != nil {
+					err = errors.Join(err, stopErr)
+				}
+				return nil, err
+			}
+		}
+		started = append(started, svc)
+	}
+	return started, nil
+}
+
+// 按注册的相反顺序调用服务的 BeforeShutdown，记录并返回所有错误
+func (e *Engine) runBeforeShutdownHooks(ctx context.Context, services []Service) error {
+	var errs []error
+	for i := len(services) - 1; i >= 0; i-- {
+		hook, ok := services[i].(BeforeShutdownHook)
+		if !ok {
+			continue
+		}
+		if err := hook.BeforeShutdown(ctx); err != nil {
+			name := hookName(services[i])
+			e.config.logger.Error(err, "service before shutdown hook failed", "service", name)
+			errs = append(errs, fmt.Errorf("service %s before shutdown: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 按注册的相反顺序调用服务的 OnStop，记录并返回所有错误
+func (e *Engine) runStopHooks(ctx context.Context, services []Service) error {
+	var errs []error
+	for i := len(services) - 1; i >= 0; i-- {
+		hook, ok := services[i].(StopHook)
+		if !ok {
+			continue
+		}
+		if err := hook.OnStop(ctx); err != nil {
+			name := hookName(services[i])
+			e.config.logger.Error(err, "service failed to stop", "service", name)
+			errs = append(errs, fmt.Errorf("service %s failed to stop: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,155 @@
+package orbit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hookService struct {
+	name     string
+	events   *[]string
+	startErr error
+	stopErr  error
+}
+
+func (s *hookService) RegisterGroup(*gin.RouterGroup) {}
+
+func (s *hookService) OnStart(context.Context) error {
+	*s.events = append(*s.events, "start:"+s.name)
+	return s.startErr
+}
+
+func (s *hookService) BeforeShutdown(context.Context) error {
+	*s.events = append(*s.events, "before:"+s.name)
+	return nil
+}
+
+func (s *hookService) OnStop(context.Context) error {
+	*s.events = append(*s.events, "stop:"+s.name)
+	return s.stopErr
+}
+
+func TestServiceHooksOrder(t *testing.T) {
+	var events []string
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), NewOptions())
+	engine.RegisterService(&hookService{name: "a", events: &events})
+	engine.RegisterService(&emptyBodyService{})
+	engine.RegisterService(&hookService{name: "b", events: &events})
+
+	engine.Run()
+	require.True(t, engine.IsRunning())
+	assert.Equal(t, []string{"start:a", "start:b"}, events)
+
+	require.NoError(t, engine.Shutdown(context.Background()))
+	assert.Equal(t, []string{"start:a", "start:b", "before:b", "before:a", "stop:b", "stop:a"}, events)
+}
+
+func TestServiceStartFailureAbortsStartup(t *testing.T) {
+	var events []string
+	startErr := errors.New("db unavailable")
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), NewOptions())
+	engine.RegisterService(&hookService{name: "a", events: &events})
+	engine.RegisterService(&hookService{name: "b", events: &events})
+	engine.RegisterService(&hookService{name: "c", events: &events, startErr: startErr})
+	engine.RegisterService(&hookService{name: "d", events: &events})
+
+	err := engine.RunContext(context.Background())
+	assert.ErrorIs(t, err, startErr)
+	assert.ErrorIs(t, engine.GetRunError(), startErr)
+	assert.False(t, engine.IsRunning())
+	assert.Nil(t, engine.listener)
+
+	// 启动失败时按相反顺序停止已启动的服务
+	assert.Equal(t, []string{"start:a", "start:b", "start:c", "stop:b", "stop:a"}, events)
+}
+
+func TestServiceStoppedWhenListenFails(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	var events []string
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	engine := NewEngine(NewConfig().WithRelease().WithPort(parsePort(t, port)), NewOptions())
+	engine.RegisterService(&hookService{name: "a", events: &events})
+
+	assert.Error(t, engine.RunContext(context.Background()))
+	assert.Equal(t, []string{"start:a", "stop:a"}, events)
+}
+
+func TestServiceStopErrorReported(t *testing.T) {
+	var events []string
+	stopErr := errors.New("flush failed")
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), NewOptions())
+	engine.RegisterService(&hookService{name: "a", events: &events, stopErr: stopErr})
+	engine.RegisterService(&hookService{name: "b", events: &events})
+
+	engine.Run()
+	require.True(t, engine.IsRunning())
+
+	// 停止钩子失败不影响其他服务停止，错误通过 Shutdown 返回
+	err := engine.Shutdown(context.Background())
+	assert.ErrorIs(t, err, stopErr)
+	assert.Equal(t, EngineStateStopped, engine.State())
+	assert.Contains(t, events, "stop:b")
+}
+
+// 通过函数实现启动钩子的服务
+type startFuncService struct {
+	name    string
+	onStart func(context.Context) error
+}
+
+func (s *startFuncService) RegisterGroup(*gin.RouterGroup) {}
+
+func (s *startFuncService) OnStart(ctx context.Context) error { return s.onStart(ctx) }
+
+func (s *startFuncService) HookName() string { return s.name }
+
+func TestServiceHookErrorsUseHookName(t *testing.T) {
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), NewOptions())
+	engine.RegisterService(&startFuncService{name: "cache-warmup", onStart: func(context.Context) error { return errors.New("timeout") }})
+
+	err := engine.RunContext(context.Background())
+	assert.EqualError(t, err, "service cache-warmup failed to start: timeout")
+
+	assert.Equal(t, "users", hookName(&scopedService{}))
+	assert.Equal(t, "*orbit.emptyBodyService", hookName(&emptyBodyService{}))
+}
+
+func TestServiceStartHookCanCallEngine(t *testing.T) {
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), NewOptions())
+	engine.RegisterService(&startFuncService{name: "introspect", onStart: func(ctx context.Context) error {
+		// 钩子执行期间调用引擎的方法不会死锁，重复启动返回错误
+		_ = engine.Routes()
+		_ = engine.Ready()
+		if err := engine.ApplyConfig(NewConfig().WithRelease().WithEphemeralPort()); err != nil {
+			return err
+		}
+		if err := engine.start(ctx); !errors.Is(err, ErrEngineStarting) {
+			return fmt.Errorf("unexpected start result: %v", err)
+		}
+		return nil
+	}})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		engine.Run()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("start hook deadlocked")
+	}
+	require.True(t, engine.IsRunning())
+	engine.Stop()
+}
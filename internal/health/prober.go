@@ -0,0 +1,126 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/shengyanli1982/orbit/internal/metric"
+)
+
+// 检查项的默认超时时间
+var defaultCheckTimeout = time.Duration(com.DefaultHealthCheckTimeoutMillis) * time.Millisecond
+
+// Prober 执行某一类探针下注册的所有检查项，并在缓存时间内复用上一次的结果
+type Prober struct {
+	probe    com.HealthProbe
+	ttl      time.Duration
+	metrics  *metric.HealthMetrics
+	mu       sync.Mutex
+	checkers []com.HealthChecker
+	report   *com.HealthReport
+	expireAt time.Time
+}
+
+// NewProber 创建探针执行器，ttl 为检查结果的缓存时间，metrics 为 nil 时不导出指标
+func NewProber(probe com.HealthProbe, ttl time.Duration, metrics *metric.HealthMetrics) *Prober {
+	return &Prober{probe: probe, ttl: ttl, metrics: metrics}
+}
+
+// Register 注册检查项，同名检查项会被替换
+func (p *Prober) Register(checker com.HealthChecker) {
+	if checker.Timeout <= 0 {
+		checker.Timeout = defaultCheckTimeout
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 注册新的检查项后，缓存的结果不再有效
+	p.report = nil
+	for i := range p.checkers {
+		if p.checkers[i].Name == checker.Name {
+			p.checkers[i] = checker
+			return
+		}
+	}
+	p.checkers = append(p.checkers, checker)
+}
+
+// Check 并发执行所有检查项并返回汇总结果，缓存时间内直接返回上一次的结果
+// 只有关键检查项失败时，汇总状态才为失败
+func (p *Prober) Check(ctx context.Context) *com.HealthReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if p.report != nil && now.Before(p.expireAt) {
+		return p.report
+	}
+
+	report := &com.HealthReport{Status: com.HealthStatusOK}
+	if len(p.checkers) > 0 {
+		report.Checks = make([]com.HealthCheckResult, len(p.checkers))
+
+		var wg sync.WaitGroup
+		for i := range p.checkers {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				report.Checks[i] = p.run(ctx, &p.checkers[i])
+			}(i)
+		}
+		wg.Wait()
+
+		for i := range report.Checks {
+			if report.Checks[i].Critical && report.Checks[i].Status != com.HealthStatusOK {
+				report.Status = com.HealthStatusFailed
+				break
+			}
+		}
+	}
+
+	p.report = report
+	p.expireAt = now.Add(p.ttl)
+	return report
+}
+
+// 执行单个检查项，超时或 panic 均视为检查失败
+func (p *Prober) run(ctx context.Context, checker *com.HealthChecker) com.HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, checker.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("health check panic: %v", r)
+			}
+		}()
+		done <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	duration := time.Since(start)
+	p.metrics.Observe(string(p.probe), checker.Name, err == nil, duration)
+
+	result := com.HealthCheckResult{
+		Name:     checker.Name,
+		Status:   com.HealthStatusOK,
+		Critical: checker.Critical,
+		Duration: duration.String(),
+	}
+	if err != nil {
+		result.Status = com.HealthStatusFailed
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/shengyanli1982/orbit/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sleepCheck(d time.Duration, err error) func(context.Context) error {
+	return func(ctx context.Context) error {
+		select {
+		case <-time.After(d):
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func TestProberWithoutCheckersIsHealthy(t *testing.T) {
+	p := NewProber(com.HealthProbeLiveness, 0, nil)
+	report := p.Check(context.Background())
+	assert.True(t, report.IsHealthy())
+	assert.Empty(t, report.Checks)
+}
+
+func TestProberRunsChecksConcurrently(t *testing.T) {
+	p := NewProber(com.HealthProbeReadiness, 0, nil)
+	p.Register(com.HealthChecker{Name: "db", Critical: true, Check: sleepCheck(100*time.Millisecond, nil)})
+	p.Register(com.HealthChecker{Name: "cache", Critical: true, Check: sleepCheck(100*time.Millisecond, nil)})
+
+	start := time.Now()
+	report := p.Check(context.Background())
+	assert.Less(t, time.Since(start), 190*time.Millisecond)
+	assert.True(t, report.IsHealthy())
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "db", report.Checks[0].Name)
+	assert.Equal(t, "cache", report.Checks[1].Name)
+}
+
+func TestProberCriticalFlag(t *testing.T) {
+	p := NewProber(com.HealthProbeReadiness, 0, nil)
+	p.Register(com.HealthChecker{Name: "optional", Check: sleepCheck(0, errors.New("degraded"))})
+
+	// 非关键检查项失败不影响探针结果
+	report := p.Check(context.Background())
+	assert.True(t, report.IsHealthy())
+	assert.Equal(t, com.HealthStatusFailed, report.Checks[0].Status)
+	assert.Equal(t, "degraded", report.Checks[0].Error)
+
+	p.Register(com.HealthChecker{Name: "db", Critical: true, Check: sleepCheck(0, errors.New("down"))})
+	report = p.Check(context.Background())
+	assert.False(t, report.IsHealthy())
+	assert.Equal(t, com.HealthStatusFailed, report.Status)
+}
+
+func TestProberTimeoutAndPanic(t *testing.T) {
+	p := NewProber(com.HealthProbeLiveness, 0, nil)
+	p.Register(com.HealthChecker{
+		Name:     "slow",
+		Timeout:  20 * time.Millisecond,
+		Critical: true,
+		Check: func(context.Context) error {
+			time.Sleep(200 * time.Millisecond) // 忽略 ctx 的检查函数同样会超时
+			return nil
+		},
+	})
+	p.Register(com.HealthChecker{Name: "panic", Check: func(context.Context) error { panic("boom") }})
+
+	start := time.Now()
+	report := p.Check(context.Background())
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+	assert.False(t, report.IsHealthy())
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+	assert.Contains(t, report.Checks[1].Error, "boom")
+}
+
+func TestProberCachesResult(t *testing.T) {
+	var calls atomic.Int32
+	check := func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}
+
+	p := NewProber(com.HealthProbeLiveness, time.Hour, nil)
+	p.Register(com.HealthChecker{Name: "counter", Check: check})
+	p.Check(context.Background())
+	p.Check(context.Background())
+	assert.Equal(t, int32(1), calls.Load())
+
+	// 注册检查项后缓存失效，同名检查项被替换
+	p.Register(com.HealthChecker{Name: "counter", Check: check})
+	report := p.Check(context.Background())
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Len(t, report.Checks, 1)
+}
+
+func TestProberExportsMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := metric.NewHealthMetrics(registry)
+	require.NoError(t, metrics.Register())
+	defer metrics.Unregister()
+
+	p := NewProber(com.HealthProbeReadiness, 0, metrics)
+	p.Register(com.HealthChecker{Name: "ok", Check: sleepCheck(0, nil)})
+	p.Register(com.HealthChecker{Name: "bad", Check: sleepCheck(0, errors.New("down"))})
+	p.Check(context.Background())
+
+	expected := `
+# HELP orbit_health_check_status Result of the last health check execution (1 = passing, 0 = failing).
+# TYPE orbit_health_check_status gauge
+orbit_health_check_status{check="bad",probe="readyz"} 0
+orbit_health_check_status{check="ok",probe="readyz"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "orbit_health_check_status"))
+	count, err := testutil.GatherAndCount(registry, "orbit_health_check_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
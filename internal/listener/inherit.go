@@ -0,0 +1,151 @@
+package listener
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// systemd 套接字激活协议约定的环境变量与起始文件描述符
+const (
+	ListenFdsStart   = 3
+	EnvListenFds     = "LISTEN_FDS"
+	EnvListenPid     = "LISTEN_PID"
+	EnvListenFdNames = "LISTEN_FDNAMES"
+
+	// 进程升级时，新进程通过该文件描述符通知旧进程已就绪
+	EnvUpgradeReadyFd = "ORBIT_UPGRADE_READY_FD"
+)
+
+// 从父进程继承的监听器
+type inheritedListener struct {
+	name     string
+	listener net.Listener
+}
+
+var (
+	inheritOnce sync.Once
+	inheritMu   sync.Mutex
+	inherited   []inheritedListener
+	readyOnce   sync.Once
+)
+
+// 解析 LISTEN_FDS 等环境变量，将继承的文件描述符转换为监听器
+// 解析完成后清除相关环境变量，避免传递给子进程
+func loadInherited() {
+	defer func() {
+		_ = os.Unsetenv(EnvListenFds)
+		_ = os.Unsetenv(EnvListenPid)
+		_ = os.Unsetenv(EnvListenFdNames)
+	}()
+
+	count, err := strconv.Atoi(os.Getenv(EnvListenFds))
+	if err != nil || count <= 0 {
+		return
+	}
+
+	// LISTEN_PID 存在时必须与当前进程一致，否则说明环境变量不是给当前进程的
+	if pid := os.Getenv(EnvListenPid); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return
+	}
+
+	names := strings.Split(os.Getenv(EnvListenFdNames), ":")
+	for i := 0; i < count; i++ {
+		name := ""
+		if i < len(names) {
+			name = names[i]
+		}
+
+		f := os.NewFile(uintptr(ListenFdsStart+i), name)
+		if f == nil {
+			continue
+		}
+
+		// FileListener 会复制文件描述符，原文件可以直接关闭
+		l, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			continue
+		}
+		inherited = append(inherited, inheritedListener{name: name, listener: l})
+	}
+}
+
+// TakeInherited 取出与名称或地址匹配的继承监听器，没有匹配时返回 nil
+// 名称优先匹配（LISTEN_FDNAMES），其次按监听地址匹配；每个监听器只会被取出一次
+func TakeInherited(name, address string) net.Listener {
+	inheritOnce.Do(loadInherited)
+
+	inheritMu.Lock()
+	defer inheritMu.Unlock()
+
+	index := -1
+	for i := range inherited {
+		if name != "" && inherited[i].name == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		for i := range inherited {
+			if isSameAddress(inherited[i].listener.Addr(), address) {
+				index = i
+				break
+			}
+		}
+	}
+	if index < 0 {
+		return nil
+	}
+
+	l := inherited[index].listener
+	inherited = append(inherited[:index], inherited[index+1:]...)
+	return l
+}
+
+// 判断监听器地址与配置的地址是否一致
+func isSameAddress(addr net.Addr, address string) bool {
+	if addr == nil || address == "" {
+		return false
+	}
+	if addr.String() == address {
+		return true
+	}
+
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	expected, err := net.ResolveTCPAddr("tcp", address)
+	if err != nil || expected.Port == 0 || expected.Port != tcpAddr.Port {
+		return false
+	}
+	// 未指定 IP 时监听所有地址
+	if expected.IP == nil || expected.IP.IsUnspecified() {
+		return tcpAddr.IP == nil || tcpAddr.IP.IsUnspecified()
+	}
+	return expected.IP.Equal(tcpAddr.IP)
+}
+
+// NotifyReady 通知发起升级的父进程当前进程已就绪
+// 仅在通过升级启动时生效，整个进程只通知一次
+func NotifyReady() {
+	readyOnce.Do(func() {
+		value := os.Getenv(EnvUpgradeReadyFd)
+		if value == "" {
+			return
+		}
+		_ = os.Unsetenv(EnvUpgradeReadyFd)
+
+		fd, err := strconv.Atoi(value)
+		if err != nil {
+			return
+		}
+		if f := os.NewFile(uintptr(fd), "upgrade-ready"); f != nil {
+			_, _ = f.Write([]byte("ready"))
+			_ = f.Close()
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package listener
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSameAddress(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8080}
+
+	assert.True(t, isSameAddress(addr, "127.0.0.1:8080"))
+	assert.True(t, isSameAddress(addr, "localhost:8080"))
+	assert.False(t, isSameAddress(addr, "127.0.0.1:8081"))
+	assert.False(t, isSameAddress(addr, "127.0.0.1:0"))
+	assert.False(t, isSameAddress(addr, ""))
+	assert.True(t, isSameAddress(&net.TCPAddr{IP: net.IPv4zero, Port: 8080}, ":8080"))
+
+	unixAddr := &net.UnixAddr{Name: "/tmp/orbit.sock", Net: "unix"}
+	assert.True(t, isSameAddress(unixAddr, "/tmp/orbit.sock"))
+	assert.False(t, isSameAddress(unixAddr, "/tmp/other.sock"))
+}
+
+func TestTakeInherited(t *testing.T) {
+	// 触发一次环境变量解析，避免后续覆盖测试数据
+	assert.Nil(t, TakeInherited("not-exist", ""))
+
+	byName, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer byName.Close()
+	byAddr, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer byAddr.Close()
+
+	inheritMu.Lock()
+	inherited = []inheritedListener{{name: "http", listener: byName}, {name: "unknown", listener: byAddr}}
+	inheritMu.Unlock()
+
+	assert.Equal(t, byName, TakeInherited("http", ""))
+	assert.Nil(t, TakeInherited("http", ""))
+	assert.Equal(t, byAddr, TakeInherited("admin", byAddr.Addr().String()))
+	assert.Nil(t, TakeInherited("admin", byAddr.Addr().String()))
+}
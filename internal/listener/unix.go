@@ -0,0 +1,125 @@
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"time"
+)
+
+// 检测残留套接字时的拨号超时时间
+const staleSocketDialTimeout = 100 * time.Millisecond
+
+// UnixSocketOptions 定义 Unix 域套接字文件的权限与属主
+type UnixSocketOptions struct {
+	Mode  os.FileMode // 套接字文件权限，0 表示保持默认
+	User  string      // 套接字文件属主用户（用户名或 UID），为空表示保持默认
+	Group string      // 套接字文件属组（组名或 GID），为空表示保持默认
+}
+
+// ListenUnix 在指定路径上创建 Unix 域套接字监听器
+// 如果路径上存在无进程监听的残留套接字文件，会先将其删除
+func ListenUnix(path string, opts UnixSocketOptions) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applySocketOptions(path, opts); err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// 删除残留的套接字文件
+// 仍有进程在监听的套接字和非套接字文件不会被删除
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("unix socket path %s exists and is not a socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, staleSocketDialTimeout)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("unix socket %s is already in use", path)
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale unix socket %s: %w", path, err)
+	}
+	return nil
+}
+
+// 设置套接字文件的权限与属主
+func applySocketOptions(path string, opts UnixSocketOptions) error {
+	if opts.Mode != 0 {
+		if err := os.Chmod(path, opts.Mode); err != nil {
+			return fmt.Errorf("failed to chmod unix socket %s: %w", path, err)
+		}
+	}
+
+	if opts.User == "" && opts.Group == "" {
+		return nil
+	}
+
+	uid, gid, err := lookupOwner(opts.User, opts.Group)
+	if err != nil {
+		return err
+	}
+	if err := os.Chown(path, uid, gid); err != nil {
+		return fmt.Errorf("failed to chown unix socket %s: %w", path, err)
+	}
+	return nil
+}
+
+// 将用户名/组名解析为 UID/GID，-1 表示保持不变
+func lookupOwner(userName, groupName string) (int, int, error) {
+	uid, gid := -1, -1
+
+	if userName != "" {
+		id, err := strconv.Atoi(userName)
+		if err != nil {
+			u, err := user.Lookup(userName)
+			if err != nil {
+				return uid, gid, fmt.Errorf("failed to lookup unix socket user %q: %w", userName, err)
+			}
+			if id, err = strconv.Atoi(u.Uid); err != nil {
+				return uid, gid, fmt.Errorf("unsupported uid %q of user %q", u.Uid, userName)
+			}
+		}
+		uid = id
+	}
+
+	if groupName != "" {
+		id, err := strconv.Atoi(groupName)
+		if err != nil {
+			g, err := user.LookupGroup(groupName)
+			if err != nil {
+				return uid, gid, fmt.Errorf("failed to lookup unix socket group %q: %w", groupName, err)
+			}
+			if id, err = strconv.Atoi(g.Gid); err != nil {
+				return uid, gid, fmt.Errorf("unsupported gid %q of group %q", g.Gid, groupName)
+			}
+		}
+		gid = id
+	}
+
+	return uid, gid, nil
+}
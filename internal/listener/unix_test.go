@@ -0,0 +1,80 @@
+//go:build !windows
+
+package listener
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnixAppliesMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orbit.sock")
+
+	l, err := ListenUnix(path, UnixSocketOptions{Mode: 0o600})
+	require.NoError(t, err)
+	defer l.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSocket)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	assert.Equal(t, path, l.Addr().String())
+}
+
+func TestListenUnixAppliesOwner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orbit.sock")
+	uid := strconv.Itoa(os.Getuid())
+	gid := strconv.Itoa(os.Getgid())
+
+	l, err := ListenUnix(path, UnixSocketOptions{User: uid, Group: gid})
+	require.NoError(t, err)
+	defer l.Close()
+
+	_, err = ListenUnix(filepath.Join(t.TempDir(), "other.sock"), UnixSocketOptions{User: "orbit-user-not-exist"})
+	assert.Error(t, err)
+}
+
+func TestListenUnixRemovesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orbit.sock")
+
+	// 创建一个不再监听的残留套接字文件
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+
+	l, err := ListenUnix(path, UnixSocketOptions{})
+	require.NoError(t, err)
+	defer l.Close()
+}
+
+func TestListenUnixRefusesSocketInUse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orbit.sock")
+
+	l, err := ListenUnix(path, UnixSocketOptions{})
+	require.NoError(t, err)
+	defer l.Close()
+
+	_, err = ListenUnix(path, UnixSocketOptions{})
+	assert.Error(t, err)
+}
+
+func TestListenUnixRefusesRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orbit.sock")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+
+	_, err := ListenUnix(path, UnixSocketOptions{})
+	assert.Error(t, err)
+
+	// 普通文件不应被删除
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,220 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadFile 读取配置文件并解析为 map，文件格式由扩展名决定：.yaml、.yml、.json、.toml
+func ReadFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	values := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q: %s", ext, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// Merge 将 src 深度合并到 dst，同名的 map 递归合并，其他值由 src 覆盖
+func Merge(dst, src map[string]any) map[string]any {
+	if dst == nil {
+		dst = make(map[string]any, len(src))
+	}
+	for key, value := range src {
+		srcMap, srcOk := value.(map[string]any)
+		dstMap, dstOk := dst[key].(map[string]any)
+		if srcOk && dstOk {
+			dst[key] = Merge(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+	return dst
+}
+
+// Set 按以 "." 分隔的路径设置值，中间层级不存在时自动创建
+func Set(values map[string]any, path string, value any) {
+	keys := strings.Split(path, ".")
+	current := values
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			current[key] = next
+		}
+		current = next
+	}
+	current[keys[len(keys)-1]] = value
+}
+
+// Decode 将 map 解码到 out 指向的结构体，字段按 json 标签匹配，存在未知字段时返回错误
+func Decode(values map[string]any, out any) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(out)
+}
+
+// Encode 将结构体编码为 map，字段名使用 json 标签
+func Encode(in any) (map[string]any, error) {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// 可以通过环境变量设置的字段
+type envField struct {
+	path string       // 以 "." 分隔的字段路径
+	typ  reflect.Type // 字段类型
+}
+
+// FromEnv 根据结构体的 json 标签，从环境变量中读取字段值
+// 环境变量名由前缀和字段路径组成，例如前缀 ORBIT 下 tls.certFile 对应 ORBIT_TLS_CERT_FILE
+func FromEnv(prefix string, environ []string, typ reflect.Type) (map[string]any, error) {
+	fields := make(map[string]envField)
+	collectEnvFields(typ, "", strings.ToUpper(prefix), fields)
+
+	values := make(map[string]any)
+	for _, kv := range environ {
+		name, raw, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		field, ok := fields[name]
+		if !ok {
+			continue
+		}
+		value, err := parseEnvValue(raw, field.typ)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for environment variable %s: %w", raw, name, err)
+		}
+		Set(values, field.path, value)
+	}
+	return values, nil
+}
+
+// 递归收集结构体中可以通过环境变量设置的字段
+func collectEnvFields(typ reflect.Type, path, env string, fields map[string]envField) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// 嵌入的结构体字段提升到当前层级
+		if field.Anonymous && name == "" {
+			collectEnvFields(field.Type, path, env, fields)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldPath, fieldEnv := name, env+"_"+toEnvName(name)
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct {
+			collectEnvFields(fieldType, fieldPath, fieldEnv, fields)
+			continue
+		}
+		fields[fieldEnv] = envField{path: fieldPath, typ: fieldType}
+	}
+}
+
+// 将驼峰形式的字段名转换为大写下划线形式，例如 remoteIPHeaders 转换为 REMOTE_IP_HEADERS
+func toEnvName(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
+
+// 按字段类型解析环境变量的值，切片使用逗号分隔
+func parseEnvValue(raw string, typ reflect.Type) (any, error) {
+	raw = strings.TrimSpace(raw)
+	switch typ.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(raw, 0, typ.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(raw, 0, typ.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, typ.Bits())
+	case reflect.Slice:
+		items := make([]any, 0)
+		if raw == "" {
+			return items, nil
+		}
+		for _, item := range strings.Split(raw, ",") {
+			value, err := parseEnvValue(item, typ.Elem())
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unsupported field type %s", typ)
+	}
+}
@@ -0,0 +1,123 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testNested struct {
+	CertFile string `json:"certFile"`
+}
+
+type Embedded struct {
+	Address string `json:"address"`
+}
+
+type testSchema struct {
+	*Embedded
+	Port     uint16      `json:"port"`
+	Mode     uint32      `json:"mode"`
+	Enabled  bool        `json:"enabled"`
+	Proxies  []string    `json:"proxies"`
+	TLS      *testNested `json:"tls"`
+	Ignored  string      `json:"-"`
+	internal string
+}
+
+func TestToEnvName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"port":                 "PORT",
+		"httpReadTimeout":      "HTTP_READ_TIMEOUT",
+		"remoteIPHeaders":      "REMOTE_IP_HEADERS",
+		"clientCAFile":         "CLIENT_CA_FILE",
+		"healthCheckCacheTTL":  "HEALTH_CHECK_CACHE_TTL",
+		"reloadIntervalMillis": "RELOAD_INTERVAL_MILLIS",
+		"TLS":                  "TLS",
+	} {
+		assert.Equal(t, expected, toEnvName(name), name)
+	}
+}
+
+func TestMergeAndSet(t *testing.T) {
+	dst := map[string]any{"port": 8080, "tls": map[string]any{"certFile": "a", "keyFile": "b"}}
+	src := map[string]any{}
+	Set(src, "tls.certFile", "c")
+	Set(src, "address", "0.0.0.0")
+
+	merged := Merge(dst, src)
+	assert.Equal(t, map[string]any{
+		"port":    8080,
+		"address": "0.0.0.0",
+		"tls":     map[string]any{"certFile": "c", "keyFile": "b"},
+	}, merged)
+}
+
+func TestReadFileFormats(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml": "port: 9090\ntls:\n  certFile: /a.crt\n",
+		"config.yml":  "port: 9090\ntls:\n  certFile: /a.crt\n",
+		"config.json": `{"port": 9090, "tls": {"certFile": "/a.crt"}}`,
+		"config.toml": "port = 9090\n[tls]\ncertFile = \"/a.crt\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		values, err := ReadFile(path)
+		require.NoError(t, err, name)
+
+		var out testSchema
+		require.NoError(t, Decode(values, &out), name)
+		assert.Equal(t, uint16(9090), out.Port, name)
+		assert.Equal(t, "/a.crt", out.TLS.CertFile, name)
+	}
+
+	path := filepath.Join(dir, "config.ini")
+	require.NoError(t, os.WriteFile(path, []byte("port=1"), 0o600))
+	_, err := ReadFile(path)
+	assert.ErrorContains(t, err, "unsupported config file format")
+
+	_, err = ReadFile(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestDecodeRejectsUnknownFields(t *testing.T) {
+	var out testSchema
+	assert.Error(t, Decode(map[string]any{"prot": 1}, &out))
+}
+
+func TestFromEnv(t *testing.T) {
+	environ := []string{
+		"APP_ADDRESS=0.0.0.0",
+		"APP_PORT=9090",
+		"APP_MODE=0660",
+		"APP_ENABLED=true",
+		"APP_PROXIES=10.0.0.0/8, 192.168.0.0/16",
+		"APP_TLS_CERT_FILE=/a.crt",
+		"APP_IGNORED=x",
+		"APP_INTERNAL=x",
+		"OTHER_PORT=1",
+		"MALFORMED",
+	}
+	values, err := FromEnv("app", environ, reflect.TypeOf(testSchema{}))
+	require.NoError(t, err)
+
+	var out testSchema
+	require.NoError(t, Decode(values, &out))
+	assert.Equal(t, "0.0.0.0", out.Address)
+	assert.Equal(t, uint16(9090), out.Port)
+	assert.Equal(t, uint32(0o660), out.Mode)
+	assert.True(t, out.Enabled)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.0.0/16"}, out.Proxies)
+	assert.Equal(t, "/a.crt", out.TLS.CertFile)
+	assert.Empty(t, out.Ignored)
+
+	_, err = FromEnv("APP", []string{"APP_PORT=70000"}, reflect.TypeOf(testSchema{}))
+	assert.ErrorContains(t, err, "APP_PORT")
+}
@@ -0,0 +1,74 @@
+package metric
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 健康检查度量标准的标签
+var healthMetricLabels = []string{"probe", "check"}
+
+// HealthMetrics 记录健康检查项的状态和耗时
+type HealthMetrics struct {
+	checkStatus   *prometheus.GaugeVec // 检查状态，1 表示通过，0 表示失败
+	checkDuration *prometheus.GaugeVec // 最近一次检查耗时（秒）
+	registry      *prometheus.Registry // Prometheus注册表
+}
+
+// 返回一个新的 HealthMetrics 实例
+func NewHealthMetrics(registry *prometheus.Registry) *HealthMetrics {
+	return NewHealthMetricsWithOptions(registry, Options{})
+}
+
+// 返回一个按选项命名的 HealthMetrics 实例
+func NewHealthMetricsWithOptions(registry *prometheus.Registry, opts Options) *HealthMetrics {
+	return &HealthMetrics{
+		checkStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   opts.namespace(),
+				Subsystem:   opts.Subsystem,
+				Name:        "health_check_status",
+				Help:        "Result of the last health check execution (1 = passing, 0 = failing).",
+				ConstLabels: opts.constLabels(),
+			},
+			healthMetricLabels,
+		),
+		checkDuration: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   opts.namespace(),
+				Subsystem:   opts.Subsystem,
+				Name:        "health_check_duration_seconds",
+				Help:        "Duration of the last health check execution in seconds.",
+				ConstLabels: opts.constLabels(),
+			},
+			healthMetricLabels,
+		),
+		registry: registry,
+	}
+}
+
+// 将度量标准注册到 Prometheus 注册表
+// 与已注册的度量标准冲突时返回错误，不会部分注册
+func (m *HealthMetrics) Register() error {
+	return registerCollectors(m.registry, m.checkStatus, m.checkDuration)
+}
+
+// 将度量标准从 Prometheus 注册表中注销
+func (m *HealthMetrics) Unregister() {
+	m.registry.Unregister(m.checkStatus)
+	m.registry.Unregister(m.checkDuration)
+}
+
+// 记录一次健康检查的结果
+func (m *HealthMetrics) Observe(probe, check string, passed bool, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	status := 0.0
+	if passed {
+		status = 1
+	}
+	m.checkStatus.WithLabelValues(probe, check).Set(status)
+	m.checkDuration.WithLabelValues(probe, check).Set(duration.Seconds())
+}
@@ -1,6 +1,7 @@
 package metric
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"sync/atomic"
@@ -14,7 +15,16 @@ import (
 )
 
 // 度量标准的标签
-var metricLabels = []string{"method", "path", "status"}
+var metricLabels = []string{"method", "path", "status", "service"}
+
+// 未记录的访问日志度量标准的标签
+var accessLogMetricLabels = []string{"method", "path", "service", "reason"}
+
+// exemplar 中链路 ID 的标签名称
+const exemplarTraceIDLabel = "trace_id"
+
+// 请求超时、限流与卸载度量标准的标签
+var timeoutMetricLabels = []string{"method", "path", "service"}
 
 // defaultRequestDurationBuckets 默认 HTTP 请求耗时桶（秒）
 // 设计原则：
@@ -32,6 +42,10 @@ type ServerMetrics struct {
 	requestCount     *prometheus.CounterVec   // 请求计数器
 	requestLatencies *prometheus.HistogramVec // 请求延迟直方图
 	requestLatency   *prometheus.GaugeVec     // 请求延迟仪表盘
+	requestTimeouts  *prometheus.CounterVec   // 请求超时计数器
+	requestLimited   *prometheus.CounterVec   // 请求限流计数器
+	requestShed      *prometheus.CounterVec   // 请求卸载计数器
+	accessLogDropped *prometheus.CounterVec   // 未记录的访问日志计数器
 	registry         *prometheus.Registry     // Prometheus注册表
 	pathNormalizer   atomic.Value             // 存储 func(*gin.Context) string
 }
@@ -47,15 +61,45 @@ func defaultPathNormalizer(c *gin.Context) string {
 	return path
 }
 
+// Options 定义度量标准的命名与引擎标识，多个引擎共享同一个注册表时用于区分各自的度量标准
+type Options struct {
+	Namespace string // 命名空间，为空时使用 orbit
+	Subsystem string // 子系统
+	Server    string // 引擎标识，非空时作为 server 常量标签添加到所有度量标准
+}
+
+// 返回度量标准的命名空间
+func (o *Options) namespace() string {
+	if o.Namespace == "" {
+		return com.OrbitName
+	}
+	return o.Namespace
+}
+
+// 返回度量标准的常量标签
+func (o *Options) constLabels() prometheus.Labels {
+	if o.Server == "" {
+		return nil
+	}
+	return prometheus.Labels{"server": o.Server}
+}
+
 // 返回一个新的 ServerMetrics 实例
 func NewServerMetrics(registry *prometheus.Registry) *ServerMetrics {
+	return NewServerMetricsWithOptions(registry, Options{})
+}
+
+// 返回一个按选项命名的 ServerMetrics 实例
+func NewServerMetricsWithOptions(registry *prometheus.Registry, opts Options) *ServerMetrics {
 	metrics := &ServerMetrics{
 		// 创建一个新的 Prometheus 计数器向量，用于记录 HTTP 请求总数
 		requestCount: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
-				Namespace: com.OrbitName,
-				Name:      "http_requests_total", // HTTP请求总数（Prometheus Counter 命名规范）
-				Help:      "Total number of HTTP requests made.",
+				Namespace:   opts.namespace(),
+				Subsystem:   opts.Subsystem,
+				Name:        "http_requests_total", // HTTP请求总数（Prometheus Counter 命名规范）
+				Help:        "Total number of HTTP requests made.",
+				ConstLabels: opts.constLabels(),
 			},
 			metricLabels,
 		),
@@ -63,10 +107,12 @@ func NewServerMetrics(registry *prometheus.Registry) *ServerMetrics {
 		// 创建一个新的 Prometheus 直方图向量，用于记录 HTTP 请求延迟
 		requestLatencies: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
-				Namespace: com.OrbitName,
-				Name:      "http_request_duration_seconds", // HTTP请求耗时分布（秒）
-				Help:      "HTTP request duration in seconds (histogram).",
-				Buckets:   defaultRequestDurationBuckets,
+				Namespace:   opts.namespace(),
+				Subsystem:   opts.Subsystem,
+				Name:        "http_request_duration_seconds", // HTTP请求耗时分布（秒）
+				Help:        "HTTP request duration in seconds (histogram).",
+				ConstLabels: opts.constLabels(),
+				Buckets:     defaultRequestDurationBuckets,
 			},
 			metricLabels,
 		),
@@ -74,13 +120,63 @@ func NewServerMetrics(registry *prometheus.Registry) *ServerMetrics {
 		// 创建一个新的 Prometheus 仪表盘向量，用于记录 HTTP 请求延迟
 		requestLatency: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
-				Namespace: com.OrbitName,
-				Name:      "http_request_duration_seconds_last", // 最近一次请求耗时（秒）
-				Help:      "Last observed HTTP request duration in seconds.",
+				Namespace:   opts.namespace(),
+				Subsystem:   opts.Subsystem,
+				Name:        "http_request_duration_seconds_last", // 最近一次请求耗时（秒）
+				Help:        "Last observed HTTP request duration in seconds.",
+				ConstLabels: opts.constLabels(),
 			},
 			metricLabels,
 		),
 
+		// 创建一个新的 Prometheus 计数器向量，用于记录超时中间件中止的请求数
+		requestTimeouts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   opts.namespace(),
+				Subsystem:   opts.Subsystem,
+				Name:        "http_request_timeouts_total", // HTTP请求超时总数
+				Help:        "Total number of HTTP requests aborted by the timeout middleware.",
+				ConstLabels: opts.constLabels(),
+			},
+			timeoutMetricLabels,
+		),
+
+		// 创建一个新的 Prometheus 计数器向量，用于记录限流中间件拒绝的请求数
+		requestLimited: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   opts.namespace(),
+				Subsystem:   opts.Subsystem,
+				Name:        "http_requests_rate_limited_total", // HTTP请求被限流总数
+				Help:        "Total number of HTTP requests rejected by the rate limit middleware.",
+				ConstLabels: opts.constLabels(),
+			},
+			timeoutMetricLabels,
+		),
+
+		// 创建一个新的 Prometheus 计数器向量，用于记录并发限制中间件卸载的请求数
+		requestShed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   opts.namespace(),
+				Subsystem:   opts.Subsystem,
+				Name:        "http_requests_shed_total", // HTTP请求被卸载总数
+				Help:        "Total number of HTTP requests shed by the concurrency limit middleware.",
+				ConstLabels: opts.constLabels(),
+			},
+			timeoutMetricLabels,
+		),
+
+		// 创建一个新的 Prometheus 计数器向量，用于记录被采样或过滤规则丢弃的访问日志数
+		accessLogDropped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   opts.namespace(),
+				Subsystem:   opts.Subsystem,
+				Name:        "http_access_logs_dropped_total", // 未记录的访问日志总数
+				Help:        "Total number of access log lines dropped by sampling or filter rules.",
+				ConstLabels: opts.constLabels(),
+			},
+			accessLogMetricLabels,
+		),
+
 		// Prometheus 注册表用于注册和收集度量标准
 		registry: registry,
 	}
@@ -108,6 +204,8 @@ func formatStatusCode(status int) string {
 		return "404"
 	case http.StatusMethodNotAllowed:
 		return "405"
+	case http.StatusTooManyRequests:
+		return "429"
 	case http.StatusInternalServerError:
 		return "500"
 	case http.StatusBadGateway:
@@ -122,10 +220,22 @@ func formatStatusCode(status int) string {
 }
 
 // 将度量标准注册到 Prometheus 注册表
-func (m *ServerMetrics) Register() {
-	m.registry.MustRegister(m.requestCount)     // 注册请求计数器
-	m.registry.MustRegister(m.requestLatencies) // 注册请求延迟直方图
-	m.registry.MustRegister(m.requestLatency)   // 注册请求延迟仪表盘
+// 与已注册的度量标准冲突时返回错误，不会部分注册
+func (m *ServerMetrics) Register() error {
+	return registerCollectors(m.registry, m.requestCount, m.requestLatencies, m.requestLatency, m.requestTimeouts, m.requestLimited, m.requestShed, m.accessLogDropped)
+}
+
+// 按顺序注册度量标准，任一注册失败时注销已注册的度量标准并返回错误
+func registerCollectors(registry *prometheus.Registry, collectors ...prometheus.Collector) error {
+	for i, collector := range collectors {
+		if err := registry.Register(collector); err != nil {
+			for _, registered := range collectors[:i] {
+				registry.Unregister(registered)
+			}
+			return fmt.Errorf("failed to register metric collector: %w", err)
+		}
+	}
+	return nil
 }
 
 // 将度量标准从 Prometheus 注册表中注销
@@ -133,36 +243,80 @@ func (m *ServerMetrics) Unregister() {
 	m.registry.Unregister(m.requestCount)     // 注销请求计数器
 	m.registry.Unregister(m.requestLatencies) // 注销请求延迟直方图
 	m.registry.Unregister(m.requestLatency)   // 注销请求延迟仪表盘
+	m.registry.Unregister(m.requestTimeouts)  // 注销请求超时计数器
+	m.registry.Unregister(m.requestLimited)   // 注销请求限流计数器
+	m.registry.Unregister(m.requestShed)      // 注销请求卸载计数器
+	m.registry.Unregister(m.accessLogDropped) // 注销未记录的访问日志计数器
 }
 
 // 增加请求计数
-func (m *ServerMetrics) IncRequestCount(method, path, status string) {
-	m.requestCount.WithLabelValues(method, path, status).Inc() // 增加请求计数
+func (m *ServerMetrics) IncRequestCount(method, path, status, service string) {
+	m.requestCount.WithLabelValues(method, path, status, service).Inc() // 增加请求计数
 }
 
 // 观察请求延迟
-func (m *ServerMetrics) ObserveRequestLatency(method, path, status string, latency float64) {
-	m.requestLatencies.WithLabelValues(method, path, status).Observe(latency) // 观察请求延迟
+func (m *ServerMetrics) ObserveRequestLatency(method, path, status, service string, latency float64) {
+	m.requestLatencies.WithLabelValues(method, path, status, service).Observe(latency) // 观察请求延迟
 }
 
 // 设置请求延迟
-func (m *ServerMetrics) SetRequestLatency(method, path, status string, latency float64) {
-	m.requestLatency.WithLabelValues(method, path, status).Set(latency) // 设置请求延迟
+func (m *ServerMetrics) SetRequestLatency(method, path, status, service string, latency float64) {
+	m.requestLatency.WithLabelValues(method, path, status, service).Set(latency) // 设置请求延迟
+}
+
+// 增加请求超时计数
+func (m *ServerMetrics) IncRequestTimeouts(method, path, service string) {
+	m.requestTimeouts.WithLabelValues(method, path, service).Inc() // 增加请求超时计数
+}
+
+// 重置请求超时计数器
+func (m *ServerMetrics) ResetRequestTimeouts(method, path, service string) {
+	m.requestTimeouts.DeleteLabelValues(method, path, service) // 删除指定标签值的请求超时计数器
+}
+
+// 增加请求限流计数
+func (m *ServerMetrics) IncRequestRateLimited(method, path, service string) {
+	m.requestLimited.WithLabelValues(method, path, service).Inc() // 增加请求限流计数
+}
+
+// 重置请求限流计数器
+func (m *ServerMetrics) ResetRequestRateLimited(method, path, service string) {
+	m.requestLimited.DeleteLabelValues(method, path, service) // 删除指定标签值的请求限流计数器
+}
+
+// 增加请求卸载计数
+func (m *ServerMetrics) IncRequestShed(method, path, service string) {
+	m.requestShed.WithLabelValues(method, path, service).Inc() // 增加请求卸载计数
+}
+
+// 重置请求卸载计数器
+func (m *ServerMetrics) ResetRequestShed(method, path, service string) {
+	m.requestShed.DeleteLabelValues(method, path, service) // 删除指定标签值的请求卸载计数器
+}
+
+// 增加未记录的访问日志计数，reason 为 common.AccessLogSampledOut 或 common.AccessLogFiltered
+func (m *ServerMetrics) IncAccessLogDropped(method, path, service, reason string) {
+	m.accessLogDropped.WithLabelValues(method, path, service, reason).Inc() // 增加未记录的访问日志计数
+}
+
+// 重置未记录的访问日志计数器
+func (m *ServerMetrics) ResetAccessLogDropped(method, path, service, reason string) {
+	m.accessLogDropped.DeleteLabelValues(method, path, service, reason) // 删除指定标签值的未记录的访问日志计数器
 }
 
 // 重置请求延迟
-func (m *ServerMetrics) ResetRequestLatency(method, path, status string) {
-	m.requestLatency.DeleteLabelValues(method, path, status) // 删除指定标签值的请求延迟
+func (m *ServerMetrics) ResetRequestLatency(method, path, status, service string) {
+	m.requestLatency.DeleteLabelValues(method, path, status, service) // 删除指定标签值的请求延迟
 }
 
 // 重置请求延迟直方图
-func (m *ServerMetrics) ResetRequestLatencies(method, path, status string) {
-	m.requestLatencies.DeleteLabelValues(method, path, status) // 删除指定标签值的请求延迟直方图
+func (m *ServerMetrics) ResetRequestLatencies(method, path, status, service string) {
+	m.requestLatencies.DeleteLabelValues(method, path, status, service) // 删除指定标签值的请求延迟直方图
 }
 
 // 重置请求计数器
-func (m *ServerMetrics) ResetRequestCount(method, path, status string) {
-	m.requestCount.DeleteLabelValues(method, path, status) // 删除指定标签值的请求计数器
+func (m *ServerMetrics) ResetRequestCount(method, path, status, service string) {
+	m.requestCount.DeleteLabelValues(method, path, status, service) // 删除指定标签值的请求计数器
 }
 
 // 重置所有度量标准
@@ -170,6 +324,10 @@ func (m *ServerMetrics) Reset() {
 	m.requestCount.Reset()     // 重置请求计数器
 	m.requestLatencies.Reset() // 重置请求延迟直方图
 	m.requestLatency.Reset()   // 重置请求延迟仪表盘
+	m.requestTimeouts.Reset()  // 重置请求超时计数器
+	m.requestLimited.Reset()   // 重置请求限流计数器
+	m.requestShed.Reset()      // 重置请求卸载计数器
+	m.accessLogDropped.Reset() // 重置未记录的访问日志计数器
 }
 
 // SetPathNormalizer 设置自定义的路径规范化函数
@@ -211,11 +369,38 @@ func (m *ServerMetrics) HandlerFunc(logger *logr.Logger) gin.HandlerFunc {
 		// 获取状态码（常见状态码走无分配快路径）
 		status := formatStatusCode(context.Writer.Status())
 
+		// 服务名称由服务路由组上的中间件在 Next 过程中设置，未命名的服务与内置路由为空
+		service := context.GetString(com.RequestServiceKey)
+
 		latency := time.Since(start).Seconds()
-		labels := []string{method, path, status}
+		labels := []string{method, path, status, service}
 
-		m.requestCount.WithLabelValues(labels...).Inc()
-		m.requestLatencies.WithLabelValues(labels...).Observe(latency)
+		// 链路被采样时以链路 ID 作为请求计数和耗时分布的 exemplar，便于从指标跳转到链路
+		// 未采样的链路不会被导出，附加 exemplar 只会指向不存在的链路
+		if traceID := context.GetString(com.RequestTraceIDKey); traceID != "" && context.GetBool(com.RequestTraceSampledKey) {
+			exemplar := prometheus.Labels{exemplarTraceIDLabel: traceID}
+			m.requestCount.WithLabelValues(labels...).(prometheus.ExemplarAdder).AddWithExemplar(1, exemplar)
+			m.requestLatencies.WithLabelValues(labels...).(prometheus.ExemplarObserver).ObserveWithExemplar(latency, exemplar)
+		} else {
+			m.requestCount.WithLabelValues(labels...).Inc()
+			m.requestLatencies.WithLabelValues(labels...).Observe(latency)
+		}
 		m.requestLatency.WithLabelValues(labels...).Set(latency)
+
+		// 超时中间件中止、限流中间件拒绝和并发限制中间件卸载的请求单独计数
+		if context.GetBool(com.RequestTimeoutKey) {
+			m.requestTimeouts.WithLabelValues(method, path, service).Inc()
+		}
+		if context.GetBool(com.RequestRateLimitedKey) {
+			m.requestLimited.WithLabelValues(method, path, service).Inc()
+		}
+		if context.GetBool(com.RequestShedKey) {
+			m.requestShed.WithLabelValues(method, path, service).Inc()
+		}
+
+		// 访问日志中间件按采样或过滤规则未记录的请求
+		if reason := context.GetString(com.RequestLogDroppedKey); reason != "" {
+			m.accessLogDropped.WithLabelValues(method, path, service, reason).Inc()
+		}
 	}
 }
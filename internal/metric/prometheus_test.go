@@ -4,11 +4,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-logr/zapr"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	dto "github.com/prometheus/client_model/go"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/shengyanli1982/orbit/utils/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -49,10 +53,10 @@ func TestServerMetricsHandlerFunc(t *testing.T) {
 	m := &dto.Metric{}
 
 	// Assert the metrics
-	_ = metrics.requestCount.WithLabelValues("GET", "/test", "200").Write(m)
+	_ = metrics.requestCount.WithLabelValues("GET", "/test", "200", "").Write(m)
 	assert.Equal(t, 1, int(m.Counter.GetValue()))
 	g := &dto.Metric{}
-	_ = metrics.requestLatency.WithLabelValues("GET", "/test", "200").Write(g)
+	_ = metrics.requestLatency.WithLabelValues("GET", "/test", "200", "").Write(g)
 	assert.NotNil(t, g.GetGauge())
 	assert.GreaterOrEqual(t, g.GetGauge().GetValue(), 0.0)
 }
@@ -60,10 +64,14 @@ func TestServerMetricsHandlerFunc(t *testing.T) {
 func TestServerMetricsPrometheusNaming(t *testing.T) {
 	registry := prometheus.NewRegistry()
 	metrics := NewServerMetrics(registry)
-	metrics.IncRequestCount("GET", "/test", "200")
-	metrics.ObserveRequestLatency("GET", "/test", "200", 0.2)
-	metrics.SetRequestLatency("GET", "/test", "200", 0.2)
-	metrics.Register()
+	metrics.IncRequestCount("GET", "/test", "200", "")
+	metrics.ObserveRequestLatency("GET", "/test", "200", "", 0.2)
+	metrics.SetRequestLatency("GET", "/test", "200", "", 0.2)
+	metrics.IncRequestTimeouts("GET", "/test", "")
+	metrics.IncRequestRateLimited("GET", "/test", "")
+	metrics.IncRequestShed("GET", "/test", "")
+	metrics.IncAccessLogDropped("GET", "/test", "", com.AccessLogSampledOut)
+	require.NoError(t, metrics.Register())
 	defer metrics.Unregister()
 
 	families, err := registry.Gather()
@@ -77,13 +85,40 @@ func TestServerMetricsPrometheusNaming(t *testing.T) {
 	assert.Contains(t, names, "orbit_http_requests_total")
 	assert.Contains(t, names, "orbit_http_request_duration_seconds")
 	assert.Contains(t, names, "orbit_http_request_duration_seconds_last")
+	assert.Contains(t, names, "orbit_http_request_timeouts_total")
+	assert.Contains(t, names, "orbit_http_requests_rate_limited_total")
+	assert.Contains(t, names, "orbit_http_requests_shed_total")
+	assert.Contains(t, names, "orbit_http_access_logs_dropped_total")
+}
+
+func TestServerMetricsCountsTimeouts(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewServerMetrics(registry)
+	logger := zapr.NewLogger(zap.NewExample())
+
+	router := gin.New()
+	router.Use(metrics.HandlerFunc(&logger))
+	router.GET("/slow", middleware.Timeout(10*time.Millisecond), func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.String(http.StatusOK, "late")
+	})
+	router.GET("/fast", middleware.Timeout(time.Second), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast", nil))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requestTimeouts.WithLabelValues("GET", "/slow", "")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.requestTimeouts.WithLabelValues("GET", "/fast", "")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requestCount.WithLabelValues("GET", "/slow", "503", "")))
 }
 
 func TestServerMetricsDurationBucketsCover120Seconds(t *testing.T) {
 	registry := prometheus.NewRegistry()
 	metrics := NewServerMetrics(registry)
-	metrics.ObserveRequestLatency("GET", "/test", "200", 95)
-	metrics.Register()
+	metrics.ObserveRequestLatency("GET", "/test", "200", "", 95)
+	require.NoError(t, metrics.Register())
 	defer metrics.Unregister()
 
 	families, err := registry.Gather()
@@ -143,7 +178,7 @@ func TestServerMetricsPathNormalization(t *testing.T) {
 
 		m := &dto.Metric{}
 		// Should use route template /users/:id, not /users/123
-		_ = metrics.requestCount.WithLabelValues("GET", "/users/:id", "200").Write(m)
+		_ = metrics.requestCount.WithLabelValues("GET", "/users/:id", "200", "").Write(m)
 		assert.Equal(t, 1, int(m.Counter.GetValue()), "Should track route template")
 	})
 
@@ -155,7 +190,7 @@ func TestServerMetricsPathNormalization(t *testing.T) {
 
 		m := &dto.Metric{}
 		// Should use "unmatched" for unregistered routes
-		_ = metrics.requestCount.WithLabelValues("GET", "unmatched", "404").Write(m)
+		_ = metrics.requestCount.WithLabelValues("GET", "unmatched", "404", "").Write(m)
 		assert.Equal(t, 1, int(m.Counter.GetValue()), "Should use unmatched label")
 	})
 }
@@ -194,7 +229,87 @@ func TestSetPathNormalizer(t *testing.T) {
 
 		m := &dto.Metric{}
 		// Should use custom "not_found" label
-		_ = metrics.requestCount.WithLabelValues("GET", "not_found", "404").Write(m)
+		_ = metrics.requestCount.WithLabelValues("GET", "not_found", "404", "").Write(m)
 		assert.Equal(t, 1, int(m.Counter.GetValue()), "Should use custom normalizer")
 	})
 }
+
+func TestServerMetricsRegisterConflict(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	first := NewServerMetrics(registry)
+	require.NoError(t, first.Register())
+
+	// 同名度量标准冲突时返回错误而不是 panic
+	second := NewServerMetrics(registry)
+	assert.Error(t, second.Register())
+
+	// 注册失败不会留下部分注册的度量标准
+	first.Unregister()
+	require.NoError(t, second.Register())
+	second.Unregister()
+}
+
+func TestServerMetricsWithOptions(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	public := NewServerMetricsWithOptions(registry, Options{Server: "public"})
+	internal := NewServerMetricsWithOptions(registry, Options{Server: "internal"})
+	require.NoError(t, public.Register())
+	require.NoError(t, internal.Register())
+
+	public.IncRequestCount("GET", "/test", "200", "")
+	internal.IncRequestCount("GET", "/test", "200", "")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	servers := make([]string, 0, 2)
+	for _, family := range families {
+		if family.GetName() != "orbit_http_requests_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "server" {
+					servers = append(servers, label.GetValue())
+				}
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{"public", "internal"}, servers)
+
+	named := NewServerMetricsWithOptions(prometheus.NewRegistry(), Options{Namespace: "shop", Subsystem: "api"})
+	require.NoError(t, named.Register())
+	named.IncRequestCount("GET", "/test", "200", "")
+	families, err = named.registry.Gather()
+	require.NoError(t, err)
+	assert.Equal(t, "shop_api_http_requests_total", families[0].GetName())
+}
+
+func TestHealthMetricsRegisterConflict(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	require.NoError(t, NewHealthMetricsWithOptions(registry, Options{Server: "public"}).Register())
+	assert.Error(t, NewHealthMetricsWithOptions(registry, Options{Server: "public"}).Register())
+	assert.NoError(t, NewHealthMetricsWithOptions(registry, Options{Server: "admin"}).Register())
+
+	// 共享注册表的引擎都需要设置标识，带与不带 server 标签的同名度量标准互相冲突
+	assert.Error(t, NewHealthMetrics(registry).Register())
+}
+
+func TestServerMetricsCountsRateLimited(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewServerMetrics(registry)
+	logger := zapr.NewLogger(zap.NewExample())
+
+	router := gin.New()
+	router.Use(metrics.HandlerFunc(&logger))
+	router.GET("/limited", middleware.RateLimit(middleware.RateLimitConfig{Rate: 1, Burst: 1}), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	for i := 0; i < 3; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/limited", nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.requestLimited.WithLabelValues("GET", "/limited", "")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.requestCount.WithLabelValues("GET", "/limited", "429", "")))
+}
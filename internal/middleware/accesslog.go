@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+)
+
+// AccessLogFilter 按访问日志策略决定请求是否记录访问日志，创建后不可修改
+// nil 表示没有规则，所有请求都记录访问日志
+type AccessLogFilter struct {
+	logClientErrors bool
+	slowThreshold   time.Duration
+	sampleRate      float64
+	routeRates      map[string]float64
+	skipPaths       map[string]struct{}
+	skipPrefixes    []string
+	skipMethods     map[string]struct{}
+	random          func() float64
+}
+
+// NewAccessLogFilter 根据访问日志策略创建过滤器，policy 为 nil 时返回 nil
+func NewAccessLogFilter(policy *com.AccessLogPolicy) *AccessLogFilter {
+	if policy == nil {
+		return nil
+	}
+
+	f := &AccessLogFilter{
+		logClientErrors: policy.LogClientErrors,
+		slowThreshold:   time.Duration(policy.SlowThreshold) * time.Millisecond,
+		sampleRate:      1,
+		routeRates:      make(map[string]float64, len(policy.RouteSampleRates)),
+		skipPaths:       make(map[string]struct{}, len(policy.SkipPaths)),
+		skipMethods:     make(map[string]struct{}, len(policy.SkipMethods)),
+		random:          rand.Float64,
+	}
+	if policy.SampleRate != nil {
+		f.sampleRate = *policy.SampleRate
+	}
+	for route, rate := range policy.RouteSampleRates {
+		f.routeRates[route] = rate
+	}
+	for _, path := range policy.SkipPaths {
+		if prefix, ok := strings.CutSuffix(path, "*"); ok {
+			f.skipPrefixes = append(f.skipPrefixes, prefix)
+		} else {
+			f.skipPaths[path] = struct{}{}
+		}
+	}
+	for _, method := range policy.SkipMethods {
+		f.skipMethods[strings.ToUpper(method)] = struct{}{}
+	}
+	return f
+}
+
+// Filtered 返回请求是否命中过滤规则，命中的请求不记录访问日志
+// 请求路径或路由模板与 SkipPaths 匹配，或者请求方法在 SkipMethods 中时命中
+func (f *AccessLogFilter) Filtered(context *gin.Context) bool {
+	if f == nil {
+		return false
+	}
+	if _, ok := f.skipMethods[context.Request.Method]; ok {
+		return true
+	}
+	path, route := context.Request.URL.Path, context.FullPath()
+	if _, ok := f.skipPaths[path]; ok {
+		return true
+	}
+	if _, ok := f.skipPaths[route]; ok && route != "" {
+		return true
+	}
+	for _, prefix := range f.skipPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sampled 在请求处理完成后返回是否记录访问日志
+// 服务端错误、处理中记录了错误的请求和慢请求始终记录，启用 LogClientErrors 时客户端错误也始终记录，其余请求按路由的采样比例记录
+func (f *AccessLogFilter) Sampled(context *gin.Context, latency time.Duration) bool {
+	if f == nil {
+		return true
+	}
+	status := context.Writer.Status()
+	if status >= http.StatusInternalServerError || len(context.Errors) > 0 {
+		return true
+	}
+	if f.logClientErrors && status >= http.StatusBadRequest {
+		return true
+	}
+	if f.slowThreshold > 0 && latency >= f.slowThreshold {
+		return true
+	}
+
+	rate, ok := f.routeRates[context.FullPath()]
+	if !ok {
+		rate = f.sampleRate
+	}
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	default:
+		return f.random() < rate
+	}
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/shengyanli1982/orbit/utils/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 使用 filter 处理一个请求，返回是否记录了访问日志以及未记录的原因
+func serveWithAccessLogFilter(t *testing.T, filter *AccessLogFilter, method, path string, status int) (bool, string) {
+	t.Helper()
+
+	logged := false
+	logEventFunc := func(_ *logr.Logger, _ *log.LogEvent) { logged = true }
+	logger := logr.Discard()
+
+	var reason string
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		reason = c.GetString(com.RequestLogDroppedKey)
+	})
+	router.Use(AccessLoggerWithFilter(&logger, logEventFunc, false, func() *AccessLogFilter { return filter }))
+	router.Handle(method, "/users/:id", func(c *gin.Context) { c.Status(status) })
+	router.Handle(method, "/internal/sync", func(c *gin.Context) { c.Status(status) })
+	router.Handle(method, "/error", func(c *gin.Context) {
+		_ = c.Error(errors.New("boom"))
+		c.Status(status)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+	return logged, reason
+}
+
+func TestAccessLogFilterNil(t *testing.T) {
+	assert.Nil(t, NewAccessLogFilter(nil))
+
+	logged, reason := serveWithAccessLogFilter(t, nil, http.MethodGet, "/users/1", http.StatusOK)
+	assert.True(t, logged)
+	assert.Empty(t, reason)
+}
+
+func TestAccessLogFilterSkipRules(t *testing.T) {
+	filter := NewAccessLogFilter(&com.AccessLogPolicy{
+		SkipPaths:   []string{"/users/:id", "/internal/*"},
+		SkipMethods: []string{"options"},
+	})
+
+	// 过滤规则优先于错误始终记录的规则
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/users/1"},
+		{http.MethodPost, "/internal/sync"},
+		{http.MethodOptions, "/error"},
+	} {
+		logged, reason := serveWithAccessLogFilter(t, filter, tc.method, tc.path, http.StatusInternalServerError)
+		assert.False(t, logged, tc.path)
+		assert.Equal(t, com.AccessLogFiltered, reason, tc.path)
+	}
+
+	logged, _ := serveWithAccessLogFilter(t, filter, http.MethodGet, "/error", http.StatusOK)
+	assert.True(t, logged)
+}
+
+func TestAccessLogFilterAlwaysLogsErrors(t *testing.T) {
+	filter := NewAccessLogFilter(&com.AccessLogPolicy{RouteSampleRates: map[string]float64{"/users/:id": 0, "/error": 0}})
+
+	logged, reason := serveWithAccessLogFilter(t, filter, http.MethodGet, "/users/1", http.StatusOK)
+	assert.False(t, logged)
+	assert.Equal(t, com.AccessLogSampledOut, reason)
+
+	logged, _ = serveWithAccessLogFilter(t, filter, http.MethodGet, "/users/1", http.StatusBadGateway)
+	assert.True(t, logged)
+	logged, _ = serveWithAccessLogFilter(t, filter, http.MethodGet, "/error", http.StatusOK)
+	assert.True(t, logged)
+
+	// 默认只对服务端错误始终记录
+	logged, _ = serveWithAccessLogFilter(t, filter, http.MethodGet, "/users/1", http.StatusNotFound)
+	assert.False(t, logged)
+	filter = NewAccessLogFilter(&com.AccessLogPolicy{LogClientErrors: true, RouteSampleRates: map[string]float64{"/users/:id": 0}})
+	logged, _ = serveWithAccessLogFilter(t, filter, http.MethodGet, "/users/1", http.StatusNotFound)
+	assert.True(t, logged)
+}
+
+func TestAccessLogFilterSampled(t *testing.T) {
+	filter := NewAccessLogFilter(&com.AccessLogPolicy{
+		SlowThreshold:    100,
+		SampleRate:       com.SampleRate(0.5),
+		RouteSampleRates: map[string]float64{"/users/:id": 0.1},
+	})
+	random := 0.3
+	filter.random = func() float64 { return random }
+
+	request := func(path string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, path, nil)
+		return c
+	}
+	router := gin.New()
+	router.GET("/users/:id", func(*gin.Context) {})
+
+	// 路由模板使用单独的采样比例，其余路由使用默认采样比例
+	users := request("/users/1")
+	router.HandleContext(users)
+	assert.False(t, filter.Sampled(users, time.Millisecond))
+	assert.True(t, filter.Sampled(request("/other"), time.Millisecond))
+	random = 0.05
+	assert.True(t, filter.Sampled(users, time.Millisecond))
+
+	// 慢请求始终记录
+	random = 0.9
+	assert.False(t, filter.Sampled(request("/other"), time.Millisecond))
+	assert.True(t, filter.Sampled(request("/other"), 100*time.Millisecond))
+
+	// 未设置默认采样比例时记录所有请求
+	filter = NewAccessLogFilter(&com.AccessLogPolicy{})
+	require.NotNil(t, filter)
+	assert.True(t, filter.Sampled(request("/other"), time.Millisecond))
+
+	// 默认采样比例为 0 时与路由的采样比例一样不记录
+	filter = NewAccessLogFilter(&com.AccessLogPolicy{SampleRate: com.SampleRate(0)})
+	assert.False(t, filter.Sampled(request("/other"), time.Millisecond))
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+)
+
+// ClientIPPolicy 描述解析真实客户端 IP 的策略，创建后不可修改，可以被多个请求并发使用
+type ClientIPPolicy struct {
+	trustedCIDRs    []*net.IPNet
+	remoteIPHeaders []string
+}
+
+// NewClientIPPolicy 根据可信代理（IP 或 CIDR）和客户端 IP 解析头创建策略
+func NewClientIPPolicy(trustedProxies, remoteIPHeaders []string) (*ClientIPPolicy, error) {
+	cidrs := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		cidr, err := parseTrustedProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+		cidrs = append(cidrs, cidr)
+	}
+
+	headers := make([]string, len(remoteIPHeaders))
+	copy(headers, remoteIPHeaders)
+	return &ClientIPPolicy{trustedCIDRs: cidrs, remoteIPHeaders: headers}, nil
+}
+
+// 将可信代理解析为 CIDR，单个 IP 视为掩码全长的 CIDR
+func parseTrustedProxy(proxy string) (*net.IPNet, error) {
+	if !strings.Contains(proxy, "/") {
+		ip := net.ParseIP(proxy)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+
+	_, cidr, err := net.ParseCIDR(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+	}
+	return cidr, nil
+}
+
+// 判断 IP 是否属于可信代理
+func (p *ClientIPPolicy) isTrusted(ip net.IP) bool {
+	for _, cidr := range p.trustedCIDRs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve 解析请求的真实客户端 IP，规则与 gin.Context.ClientIP 一致
+// 仅当直连地址属于可信代理时才读取解析头，并从右向左跳过可信代理，无法解析时返回空字符串
+func (p *ClientIPPolicy) Resolve(req *http.Request) string {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(req.RemoteAddr))
+	if err != nil {
+		return ""
+	}
+	remoteIP := net.ParseIP(host)
+	if remoteIP == nil {
+		return ""
+	}
+
+	if p.isTrusted(remoteIP) {
+		for _, name := range p.remoteIPHeaders {
+			if ip, ok := p.resolveHeader(req.Header.Get(name)); ok {
+				return ip
+			}
+		}
+	}
+	return remoteIP.String()
+}
+
+// 从右向左解析代理头中的 IP 列表，返回第一个非可信代理的地址
+func (p *ClientIPPolicy) resolveHeader(header string) (string, bool) {
+	if header == "" {
+		return "", false
+	}
+	items := strings.Split(header, ",")
+	for i := len(items) - 1; i >= 0; i-- {
+		value := strings.TrimSpace(items[i])
+		ip := net.ParseIP(value)
+		if ip == nil {
+			break
+		}
+		if i == 0 || !p.isTrusted(ip) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// 返回一个按策略解析真实客户端 IP 的 Gin 中间件
+// 解析结果写入 com.HttpHeaderClientIP 请求头，配合 gin.Engine.TrustedPlatform 使 ClientIP 返回该值
+// 每个请求开始时获取一次策略，保证同一请求内看到一致的配置
+func ClientIP(policy func() *ClientIPPolicy) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		header := context.Request.Header
+		if ip := policy().Resolve(context.Request); ip != "" {
+			header.Set(com.HttpHeaderClientIP, ip)
+		} else {
+			header.Del(com.HttpHeaderClientIP)
+		}
+		context.Next()
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClientIPPolicyInvalidProxy(t *testing.T) {
+	_, err := NewClientIPPolicy([]string{"10.0.0.0/8", "invalid-cidr"}, nil)
+	assert.Error(t, err)
+
+	_, err = NewClientIPPolicy([]string{"10.0.0.1", "::1", "fd00::/8"}, nil)
+	assert.NoError(t, err)
+}
+
+func TestClientIPPolicyResolve(t *testing.T) {
+	policy, err := NewClientIPPolicy([]string{"10.0.0.0/8"}, []string{"X-Forwarded-For", "X-Real-IP"})
+	require.NoError(t, err)
+
+	newRequest := func(remoteAddr string, headers map[string]string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+
+	// 可信代理转发时，跳过链路中的可信代理
+	assert.Equal(t, "1.2.3.4", policy.Resolve(newRequest("10.0.0.1:1234", map[string]string{"X-Forwarded-For": "1.2.3.4, 10.0.0.2"})))
+	// 第一个解析头无效时使用下一个
+	assert.Equal(t, "5.6.7.8", policy.Resolve(newRequest("10.0.0.1:1234", map[string]string{"X-Real-IP": "5.6.7.8"})))
+	// 非可信代理直连时忽略解析头
+	assert.Equal(t, "8.8.8.8", policy.Resolve(newRequest("8.8.8.8:1234", map[string]string{"X-Forwarded-For": "1.2.3.4"})))
+	// 无法解析的直连地址
+	assert.Equal(t, "", policy.Resolve(newRequest("invalid", nil)))
+}
+
+func TestClientIPMiddlewareSwapsPolicy(t *testing.T) {
+	trustAll, err := NewClientIPPolicy([]string{"0.0.0.0/0"}, []string{"X-Forwarded-For"})
+	require.NoError(t, err)
+	trustNone, err := NewClientIPPolicy(nil, []string{"X-Forwarded-For"})
+	require.NoError(t, err)
+
+	var current atomic.Pointer[ClientIPPolicy]
+	current.Store(trustAll)
+
+	engine := gin.New()
+	engine.TrustedPlatform = com.HttpHeaderClientIP
+	engine.Use(ClientIP(current.Load))
+	engine.GET("/ip", func(c *gin.Context) {
+		c.String(http.StatusOK, c.ClientIP())
+	})
+
+	request := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = "9.9.9.9:1234"
+		req.Header.Set("X-Forwarded-For", "1.2.3.4")
+		req.Header.Set(com.HttpHeaderClientIP, "6.6.6.6")
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder.Body.String()
+	}
+
+	assert.Equal(t, "1.2.3.4", request())
+
+	current.Store(trustNone)
+	assert.Equal(t, "9.9.9.9", request())
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/shengyanli1982/orbit/internal/tlsutil"
+)
+
+// 返回一个从 TLS 连接中提取客户端证书身份的 Gin 中间件
+// 客户端证书通过校验后，身份信息会被写入 gin.Context，供业务处理函数和访问日志使用
+func PeerIdentity() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if identity := tlsutil.PeerIdentityFromState(context.Request.TLS); identity != nil {
+			context.Set(com.PeerIdentityKey, identity)
+		}
+		context.Next()
+	}
+}
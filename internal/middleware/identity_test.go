@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
+	"github.com/shengyanli1982/orbit/utils/httptool"
+	"github.com/shengyanli1982/orbit/utils/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerIdentityAddsIdentityToContextAndAccessLog(t *testing.T) {
+	router := gin.New()
+	logger := logr.Discard()
+
+	var gotSubject, gotSPIFFE string
+	var gotSANs []string
+	logEventFunc := func(_ *logr.Logger, event *log.LogEvent) {
+		gotSubject = event.PeerSubject
+		gotSANs = event.PeerSANs
+		gotSPIFFE = event.PeerSPIFFEID
+	}
+
+	router.Use(PeerIdentity(), AccessLogger(&logger, logEventFunc, false))
+	router.GET("/test", func(c *gin.Context) {
+		identity, ok := httptool.GetPeerIdentityFromContext(c)
+		if !ok {
+			c.String(http.StatusUnauthorized, "anonymous")
+			return
+		}
+		c.String(http.StatusOK, identity.SPIFFEID)
+	})
+
+	spiffe, _ := url.Parse("spiffe://example.org/sa/client")
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "client"},
+		DNSNames: []string{"client.example.org"},
+		URIs:     []*url.URL{spiffe},
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "spiffe://example.org/sa/client", recorder.Body.String())
+	assert.Equal(t, "CN=client", gotSubject)
+	assert.Equal(t, []string{"client.example.org", "spiffe://example.org/sa/client"}, gotSANs)
+	assert.Equal(t, "spiffe://example.org/sa/client", gotSPIFFE)
+}
+
+func TestPeerIdentityWithoutTLS(t *testing.T) {
+	router := gin.New()
+	logger := logr.Discard()
+
+	var gotSubject string
+	logEventFunc := func(_ *logr.Logger, event *log.LogEvent) {
+		gotSubject = event.PeerSubject
+	}
+
+	router.Use(PeerIdentity(), AccessLogger(&logger, logEventFunc, false))
+	router.GET("/test", func(c *gin.Context) {
+		_, ok := httptool.GetPeerIdentityFromContext(c)
+		assert.False(t, ok)
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, gotSubject)
+}
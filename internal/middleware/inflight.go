@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 返回一个统计正在处理中请求数量的 Gin 中间件
+// 请求进入时计数加一，处理结束（包括发生 panic）时计数减一
+func InFlight(counter *atomic.Int64) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		counter.Add(1)
+		defer counter.Add(-1)
+		context.Next()
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInFlightCountsActiveRequests(t *testing.T) {
+	var counter atomic.Int64
+	var during int64
+
+	engine := gin.New()
+	engine.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	engine.Use(InFlight(&counter))
+	engine.GET("/ok", func(c *gin.Context) {
+		during = counter.Load()
+		c.Status(http.StatusOK)
+	})
+	engine.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, int64(1), during)
+	assert.Equal(t, int64(0), counter.Load())
+
+	// 处理函数 panic 时计数同样会被释放
+	recorder = httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, int64(0), counter.Load())
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/shengyanli1982/orbit/utils/requestid"
+)
+
+// 返回一个为请求分配请求 ID 的 Gin 中间件
+// 请求头中的 X-Request-Id 合法时沿用，否则使用 generate 生成新的请求 ID
+// 请求 ID 保存在 gin.Context 中，同时写回请求头并在响应头中返回
+func RequestID(generate requestid.Generator) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		id := context.GetHeader(com.HttpHeaderRequestID)
+		if !requestid.IsValid(id) {
+			id = generate()
+			context.Request.Header.Set(com.HttpHeaderRequestID, id)
+		}
+		context.Set(com.RequestIDKey, id)
+		context.Header(com.HttpHeaderRequestID, id)
+		context.Next()
+	}
+}
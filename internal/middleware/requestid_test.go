@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/shengyanli1982/orbit/utils/httptool"
+	"github.com/shengyanli1982/orbit/utils/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRequestID(t *testing.T) {
+	router := gin.New()
+	router.Use(RequestID(func() string { return "generated" }))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, httptool.GetRequestIDFromContext(c))
+	})
+
+	request := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		if id != "" {
+			req.Header.Set(com.HttpHeaderRequestID, id)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// 缺少请求 ID 时生成新的请求 ID
+	recorder := request("")
+	assert.Equal(t, "generated", recorder.Body.String())
+	assert.Equal(t, "generated", recorder.Header().Get(com.HttpHeaderRequestID))
+
+	// 合法的请求 ID 被沿用
+	recorder = request("client-id.1")
+	assert.Equal(t, "client-id.1", recorder.Body.String())
+	assert.Equal(t, "client-id.1", recorder.Header().Get(com.HttpHeaderRequestID))
+
+	// 不合法的请求 ID 被替换
+	recorder = request("bad id<script>")
+	assert.Equal(t, "generated", recorder.Body.String())
+	assert.Equal(t, "generated", recorder.Header().Get(com.HttpHeaderRequestID))
+}
+
+func TestRequestIDInLogs(t *testing.T) {
+	buff := new(bytes.Buffer)
+	logger := log.NewZapLogger(zapcore.AddSync(buff), false).GetLogrLogger()
+
+	router := gin.New()
+	router.Use(RequestID(func() string { return "generated" }), AccessLogger(logger, log.DefaultAccessEventFunc, false))
+	router.GET("/test", func(c *gin.Context) {
+		httptool.GetLoggerFromContext(c).Info("handler log")
+		c.Status(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	logs := buff.String()
+	assert.Contains(t, logs, `"message":"handler log","requestId":"generated"`)
+	assert.Contains(t, logs, `"id":"generated"`)
+}
@@ -155,6 +155,12 @@ func isOriginAllowed(origin string, allowed []string) bool {
 
 // 返回一个用于记录访问日志的 Gin 中间件
 func AccessLogger(logger *logr.Logger, logEventFunc com.LogEventFunc, record bool) gin.HandlerFunc {
+	return AccessLoggerWithFilter(logger, logEventFunc, record, nil)
+}
+
+// 返回一个按过滤器采样和过滤访问日志的 Gin 中间件，filter 在每个请求开始时调用一次，为 nil 或返回 nil 时记录所有请求
+// 未记录的请求在 gin.Context 中标记原因，由度量标准中间件计数
+func AccessLoggerWithFilter(logger *logr.Logger, logEventFunc com.LogEventFunc, record bool, filter func() *AccessLogFilter) gin.HandlerFunc {
 	return func(context *gin.Context) {
 		// 预先获取所有需要的值，避免重复获取
 		req := context.Request
@@ -162,19 +168,37 @@ func AccessLogger(logger *logr.Logger, logEventFunc com.LogEventFunc, record boo
 		method := req.Method
 		path := httptool.GenerateRequestPath(context)
 		requestContentType := httptool.StringFilterFlags(header.Get(com.HttpHeaderContentType))
-		requestID := header.Get(com.HttpHeaderRequestID)
+		requestID := httptool.GetRequestIDFromContext(context)
 		forwardedFor := header.Get(com.HttpHeaderForwardedFor)
 		userAgent := req.UserAgent()
 		remoteAddr := req.RemoteAddr
 		rawQuery := req.URL.RawQuery
 
-		// 设置请求日志记录器
-		context.Set(com.RequestLoggerKey, logger)
+		// 设置请求日志记录器，存在请求 ID 或链路 ID 时日志记录器带有 requestId、traceId 字段
+		traceID := context.GetString(com.RequestTraceIDKey)
+		if requestID != "" || traceID != "" {
+			requestLogger := *logger
+			if requestID != "" {
+				requestLogger = requestLogger.WithValues("requestId", requestID)
+			}
+			if traceID != "" {
+				requestLogger = requestLogger.WithValues("traceId", traceID)
+			}
+			context.Set(com.RequestLoggerKey, &requestLogger)
+		} else {
+			context.Set(com.RequestLoggerKey, logger)
+		}
 		start := time.Now()
 
+		var rules *AccessLogFilter
+		if filter != nil {
+			rules = filter()
+		}
+		filtered := rules.Filtered(context)
+
 		// 只在需要时才记录请求体
 		var requestBody []byte
-		if record && httptool.CanRecordContextBody(header) {
+		if record && !filtered && httptool.CanRecordContextBody(header) {
 			requestBody, _ = httptool.GenerateRequestBody(context)
 		}
 
@@ -187,6 +211,17 @@ func AccessLogger(logger *logr.Logger, logEventFunc com.LogEventFunc, record boo
 			}
 		}
 
+		// 命中过滤规则或未被采样的请求不记录访问日志
+		latency := time.Since(start)
+		if filtered {
+			context.Set(com.RequestLogDroppedKey, com.AccessLogFiltered)
+			return
+		}
+		if !rules.Sampled(context, latency) {
+			context.Set(com.RequestLogDroppedKey, com.AccessLogSampledOut)
+			return
+		}
+
 		// 从对象池获取事件对象
 		event := com.LogEventPool.Get()
 		defer com.LogEventPool.Put(event)
@@ -194,23 +229,38 @@ func AccessLogger(logger *logr.Logger, logEventFunc com.LogEventFunc, record boo
 		// 一次性设置所有字段
 		event.Message = "http server access log"
 		event.ID = requestID
+		event.TraceID = traceID
+		event.SpanID = context.GetString(com.RequestSpanIDKey)
 		event.IP = remoteAddr
 		event.EndPoint = remoteAddr
 		event.Path = path
 		event.Method = method
+		event.Service = context.GetString(com.RequestServiceKey)
 		event.Code = context.Writer.Status()
 		event.Status = http.StatusText(event.Code)
-		event.Latency = formatDurationMs(time.Since(start).Nanoseconds())
+		event.Latency = formatDurationMs(latency.Nanoseconds())
 		event.Agent = userAgent
 		event.ForwardedFor = forwardedFor
 		event.ReqContentType = requestContentType
 		event.ReqQuery = rawQuery
 		event.ReqBody = conver.BytesToString(requestBody)
+		if identity, ok := httptool.GetPeerIdentityFromContext(context); ok {
+			event.PeerSubject = identity.Subject
+			event.PeerSANs = identity.SANs()
+			event.PeerSPIFFEID = identity.SPIFFEID
+		}
 
 		logEventFunc(logger, event)
 	}
 }
 
+// 返回一个标记请求所属服务的 Gin 中间件，服务名称用于度量标准和访问日志
+func ServiceName(name string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.Set(com.RequestServiceKey, name)
+	}
+}
+
 // 返回一个用于处理 panic 恢复的 Gin 中间件
 func Recovery(logger *logr.Logger, logEventFunc com.LogEventFunc) gin.HandlerFunc {
 	return func(context *gin.Context) {
@@ -221,7 +271,7 @@ func Recovery(logger *logr.Logger, logEventFunc com.LogEventFunc) gin.HandlerFun
 				clientIP := context.ClientIP()
 				method := req.Method
 				path := httptool.GenerateRequestPath(context)
-				requestID := context.GetHeader(com.HttpHeaderRequestID)
+				requestID := httptool.GetRequestIDFromContext(context)
 				forwardedFor := req.Header.Get(com.HttpHeaderForwardedFor)
 				userAgent := req.UserAgent()
 				remoteAddr := req.RemoteAddr
@@ -265,10 +315,13 @@ func Recovery(logger *logr.Logger, logEventFunc com.LogEventFunc) gin.HandlerFun
 				// 一次性设置所有字段
 				event.Message = "http server recovery from panic"
 				event.ID = requestID
+				event.TraceID = context.GetString(com.RequestTraceIDKey)
+				event.SpanID = context.GetString(com.RequestSpanIDKey)
 				event.IP = clientIP
 				event.EndPoint = remoteAddr
 				event.Path = path
 				event.Method = method
+				event.Service = context.GetString(com.RequestServiceKey)
 				event.Code = statusCode
 				event.Status = http.StatusText(statusCode)
 				event.Agent = userAgent
@@ -3,6 +3,7 @@ package middleware
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"sync"
@@ -456,3 +457,26 @@ func TestLogrRecovery(t *testing.T) {
 	// Assert that the log buffer contains the expected message
 	assert.Contains(t, buff.String(), "http server recovery from panic", "buffer should contain the message")
 }
+
+func TestServiceNameInAccessLog(t *testing.T) {
+	router := gin.New()
+
+	var service string
+	logEventFunc := func(_ *logr.Logger, event *log.LogEvent) {
+		service = event.Service
+	}
+	logger := log.NewZapLogger(zapcore.AddSync(io.Discard), false).GetLogrLogger()
+
+	router.Use(AccessLogger(logger, logEventFunc, false))
+	router.Group("/users", ServiceName("users")).GET("", func(c *gin.Context) {
+		assert.Equal(t, "users", c.GetString(com.RequestServiceKey))
+		c.Status(http.StatusOK)
+	})
+	router.GET("/other", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, "users", service)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/other", nil))
+	assert.Equal(t, "", service)
+}
@@ -0,0 +1,130 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	com "github.com/shengyanli1982/orbit/common"
+)
+
+// 支持的 TLS 版本名称映射
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseMinVersion 将 "1.2"、"TLS1.3" 等形式的版本名称解析为 tls 包中的版本常量
+func ParseMinVersion(version string) (uint16, error) {
+	v := strings.TrimSpace(version)
+	v = strings.TrimPrefix(strings.ToUpper(v), "TLS")
+	v = strings.TrimPrefix(v, "V")
+	if id, ok := tlsVersions[strings.TrimSpace(v)]; ok {
+		return id, nil
+	}
+	return 0, fmt.Errorf("unsupported tls version %q", version)
+}
+
+// ParseCipherSuites 将加密套件名称解析为 tls 包中的套件 ID
+// 名称与 tls.CipherSuiteName 的输出保持一致，例如 TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+// tls.InsecureCipherSuites 中的不安全套件会被拒绝
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+	insecure := make(map[string]struct{})
+	for _, suite := range tls.InsecureCipherSuites() {
+		insecure[suite.Name] = struct{}{}
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		if _, ok := insecure[strings.TrimSpace(name)]; ok {
+			return nil, fmt.Errorf("insecure tls cipher suite %q is not allowed", name)
+		}
+		id, ok := known[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// NewServerConfig 根据 TLS 配置构建服务端使用的 tls.Config，证书由 reloader 动态提供
+func NewServerConfig(conf *com.TLSConfig, reloader *CertReloader) (*tls.Config, error) {
+	minVersion := conf.MinVersion
+	if strings.TrimSpace(minVersion) == "" {
+		minVersion = com.DefaultTLSMinVersion
+	}
+	version, err := ParseMinVersion(minVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	suites, err := ParseCipherSuites(conf.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth, err := ParseClientAuth(conf.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验客户端证书时必须提供 CA 证书包，避免误用系统根证书
+	var clientCAs *x509.CertPool
+	if clientAuth != tls.NoClientCert {
+		if strings.TrimSpace(conf.ClientCAFile) == "" {
+			return nil, fmt.Errorf("tls client auth %q requires a client ca file", conf.ClientAuth)
+		}
+		if clientCAs, err = LoadCertPool(conf.ClientCAFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return &tls.Config{
+		MinVersion:     version,
+		CipherSuites:   suites,
+		GetCertificate: reloader.GetCertificate,
+		ClientAuth:     clientAuth,
+		ClientCAs:      clientCAs,
+	}, nil
+}
+
+// ParseClientAuth 将客户端证书校验模式名称解析为 tls.ClientAuthType
+func ParseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", com.TLSClientAuthNone:
+		return tls.NoClientCert, nil
+	case com.TLSClientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven, nil
+	case com.TLSClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("unsupported tls client auth mode %q", mode)
+	}
+}
+
+// LoadCertPool 从 PEM 文件加载 CA 证书包
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ca file %s: %w", file, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no valid certificate found in ca file %s", file)
+	}
+	return pool, nil
+}
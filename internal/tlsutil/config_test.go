@@ -0,0 +1,106 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"testing"
+
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMinVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected uint16
+	}{
+		{"1.2", tls.VersionTLS12},
+		{"1.3", tls.VersionTLS13},
+		{"TLS1.3", tls.VersionTLS13},
+		{"tlsv1.2", tls.VersionTLS12},
+	}
+
+	for _, tt := range tests {
+		version, err := ParseMinVersion(tt.input)
+		assert.NoError(t, err, tt.input)
+		assert.Equal(t, tt.expected, version, tt.input)
+	}
+
+	_, err := ParseMinVersion("2.0")
+	assert.Error(t, err)
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	ids, err := ParseCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, ids)
+
+	ids, err = ParseCipherSuites(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, ids)
+
+	_, err = ParseCipherSuites([]string{"TLS_UNKNOWN"})
+	assert.Error(t, err)
+
+	_, err = ParseCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"})
+	assert.ErrorContains(t, err, "insecure tls cipher suite")
+}
+
+func TestNewServerConfig(t *testing.T) {
+	r := &CertReloader{}
+	conf, err := NewServerConfig(&com.TLSConfig{MinVersion: "1.3"}, r)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), conf.MinVersion)
+	assert.NotNil(t, conf.GetCertificate)
+
+	conf, err = NewServerConfig(&com.TLSConfig{}, r)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), conf.MinVersion)
+
+	_, err = NewServerConfig(&com.TLSConfig{CipherSuites: []string{"bad"}}, r)
+	assert.Error(t, err)
+}
+
+func TestParseClientAuth(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected tls.ClientAuthType
+	}{
+		{"", tls.NoClientCert},
+		{com.TLSClientAuthNone, tls.NoClientCert},
+		{com.TLSClientAuthVerifyIfGiven, tls.VerifyClientCertIfGiven},
+		{com.TLSClientAuthRequire, tls.RequireAndVerifyClientCert},
+	}
+
+	for _, tt := range tests {
+		mode, err := ParseClientAuth(tt.input)
+		assert.NoError(t, err, tt.input)
+		assert.Equal(t, tt.expected, mode, tt.input)
+	}
+
+	_, err := ParseClientAuth("optional")
+	assert.Error(t, err)
+}
+
+func TestNewServerConfigClientAuth(t *testing.T) {
+	r := &CertReloader{}
+
+	// 启用客户端证书校验时必须提供 CA 证书包
+	_, err := NewServerConfig(&com.TLSConfig{ClientAuth: com.TLSClientAuthRequire}, r)
+	assert.Error(t, err)
+
+	caFile, _ := writeTestKeyPair(t, t.TempDir(), "ca")
+	conf, err := NewServerConfig(&com.TLSConfig{ClientAuth: com.TLSClientAuthRequire, ClientCAFile: caFile}, r)
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, conf.ClientAuth)
+	assert.NotNil(t, conf.ClientCAs)
+}
+
+func TestLoadCertPool(t *testing.T) {
+	_, err := LoadCertPool("/not/exist.pem")
+	assert.Error(t, err)
+
+	_, keyFile := writeTestKeyPair(t, t.TempDir(), "ca")
+	_, err = LoadCertPool(keyFile)
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"strings"
+
+	com "github.com/shengyanli1982/orbit/common"
+)
+
+// SPIFFE ID 的 URI 前缀
+const spiffeScheme = "spiffe"
+
+// PeerIdentityFromState 从 TLS 连接状态中提取经过校验的客户端身份
+// 仅在客户端证书通过校验时返回身份，否则返回 nil
+func PeerIdentityFromState(state *tls.ConnectionState) *com.PeerIdentity {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return PeerIdentityFromCertificate(state.VerifiedChains[0][0])
+}
+
+// PeerIdentityFromCertificate 从证书中提取主题与 SAN 信息
+func PeerIdentityFromCertificate(cert *x509.Certificate) *com.PeerIdentity {
+	if cert == nil {
+		return nil
+	}
+
+	identity := &com.PeerIdentity{
+		Subject:        cert.Subject.String(),
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+	}
+
+	if len(cert.IPAddresses) > 0 {
+		identity.IPAddresses = make([]string, 0, len(cert.IPAddresses))
+		for _, ip := range cert.IPAddresses {
+			identity.IPAddresses = append(identity.IPAddresses, ip.String())
+		}
+	}
+
+	if len(cert.URIs) > 0 {
+		identity.URIs = make([]string, 0, len(cert.URIs))
+		for _, uri := range cert.URIs {
+			identity.URIs = append(identity.URIs, uri.String())
+			// 按 SPIFFE 规范，一个 SVID 只包含一个 SPIFFE ID
+			if identity.SPIFFEID == "" && strings.EqualFold(uri.Scheme, spiffeScheme) {
+				identity.SPIFFEID = uri.String()
+			}
+		}
+	}
+
+	return identity
+}
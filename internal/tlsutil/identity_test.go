@@ -0,0 +1,51 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerIdentityFromCertificate(t *testing.T) {
+	spiffe, _ := url.Parse("spiffe://example.org/ns/default/sa/api")
+	other, _ := url.Parse("https://example.org/client")
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "client", Organization: []string{"orbit"}},
+		DNSNames:       []string{"client.example.org"},
+		EmailAddresses: []string{"ops@example.org"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+		URIs:           []*url.URL{other, spiffe},
+	}
+
+	identity := PeerIdentityFromCertificate(cert)
+	assert.Equal(t, "CN=client,O=orbit", identity.Subject)
+	assert.Equal(t, []string{"client.example.org"}, identity.DNSNames)
+	assert.Equal(t, []string{"10.0.0.1"}, identity.IPAddresses)
+	assert.Equal(t, "spiffe://example.org/ns/default/sa/api", identity.SPIFFEID)
+	assert.Equal(t, []string{
+		"client.example.org",
+		"ops@example.org",
+		"10.0.0.1",
+		"https://example.org/client",
+		"spiffe://example.org/ns/default/sa/api",
+	}, identity.SANs())
+}
+
+func TestPeerIdentityFromStateRequiresVerifiedChain(t *testing.T) {
+	assert.Nil(t, PeerIdentityFromState(nil))
+
+	// 未经校验的证书不应产生身份
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "client"}}
+	assert.Nil(t, PeerIdentityFromState(&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}))
+
+	identity := PeerIdentityFromState(&tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	})
+	assert.Equal(t, "CN=client", identity.Subject)
+}
@@ -0,0 +1,116 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// 证书文件的状态快照，用于判断文件是否发生变化
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// CertReloader 从磁盘加载证书，并在证书文件变化时自动重新加载
+// 已建立的连接继续使用旧证书，新的 TLS 握手使用最新加载的证书
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	logger   *logr.Logger
+	cert     atomic.Pointer[tls.Certificate]
+	mu       sync.Mutex
+	stamps   [2]fileStamp
+}
+
+// NewCertReloader 创建证书重载器并立即加载一次证书
+func NewCertReloader(certFile, keyFile string, logger *logr.Logger) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, logger: logger}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate 实现 tls.Config.GetCertificate，返回当前生效的证书
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// Reload 从磁盘重新加载证书和私钥，加载失败时保留当前证书
+func (r *CertReloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stamps, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tls key pair, cert: %s, key: %s: %w", r.certFile, r.keyFile, err)
+	}
+
+	r.cert.Store(&cert)
+	r.stamps = stamps
+	return nil
+}
+
+// Watch 按指定间隔检查证书文件，发现变化后重新加载，直到 ctx 被取消
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				r.logger.Error(err, "failed to reload tls certificate, keep using the previous one")
+				continue
+			}
+			r.logger.Info("tls certificate reloaded", "cert", r.certFile, "key", r.keyFile)
+		}
+	}
+}
+
+// 判断证书或私钥文件自上次加载后是否发生变化
+func (r *CertReloader) changed() bool {
+	stamps, err := r.stat()
+	if err != nil {
+		// 轮换过程中文件可能短暂缺失，等待下一次检查
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return stamps != r.stamps
+}
+
+// 获取证书和私钥文件的状态快照
+func (r *CertReloader) stat() ([2]fileStamp, error) {
+	var stamps [2]fileStamp
+	for i, name := range [2]string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return stamps, err
+		}
+		stamps[i] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
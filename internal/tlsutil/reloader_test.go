@@ -0,0 +1,118 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 生成自签名证书并写入指定目录
+func writeTestKeyPair(t *testing.T, dir, commonName string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return certFile, keyFile
+}
+
+func currentCommonName(t *testing.T, r *CertReloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestNewCertReloaderMissingFile(t *testing.T) {
+	logger := logr.Discard()
+	_, err := NewCertReloader("/not/exist.crt", "/not/exist.key", &logger)
+	assert.Error(t, err)
+}
+
+func TestCertReloaderReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, dir, "first")
+	logger := logr.Discard()
+
+	r, err := NewCertReloader(certFile, keyFile, &logger)
+	require.NoError(t, err)
+	assert.Equal(t, "first", currentCommonName(t, r))
+
+	writeTestKeyPair(t, dir, "second")
+	require.NoError(t, r.Reload())
+	assert.Equal(t, "second", currentCommonName(t, r))
+}
+
+func TestCertReloaderKeepsCertificateOnBrokenFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, dir, "first")
+	logger := logr.Discard()
+
+	r, err := NewCertReloader(certFile, keyFile, &logger)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o600))
+	assert.Error(t, r.Reload())
+	assert.Equal(t, "first", currentCommonName(t, r))
+}
+
+func TestCertReloaderWatch(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, dir, "first")
+	logger := logr.Discard()
+
+	r, err := NewCertReloader(certFile, keyFile, &logger)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Watch(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	// 确保文件修改时间发生变化
+	future := time.Now().Add(time.Minute)
+	writeTestKeyPair(t, dir, "rotated")
+	require.NoError(t, os.Chtimes(certFile, future, future))
+
+	assert.Eventually(t, func() bool {
+		return currentCommonName(t, r) == "rotated"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
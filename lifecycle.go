@@ -0,0 +1,65 @@
+package orbit
+
+import "errors"
+
+// EngineState 表示引擎的生命周期状态
+// 状态迁移：new → running → stopping → stopped → running
+type EngineState int32
+
+const (
+	EngineStateNew      EngineState = iota // 已创建，尚未启动
+	EngineStateRunning                     // 正在提供服务
+	EngineStateStopping                    // 正在停止
+	EngineStateStopped                     // 已停止，可以再次启动
+)
+
+// 生命周期状态名称
+var engineStateNames = [...]string{
+	EngineStateNew:      "new",
+	EngineStateRunning:  "running",
+	EngineStateStopping: "stopping",
+	EngineStateStopped:  "stopped",
+}
+
+// String 返回生命周期状态的名称
+func (s EngineState) String() string {
+	if s < 0 || int(s) >= len(engineStateNames) {
+		return "unknown"
+	}
+	return engineStateNames[s]
+}
+
+// 非法的生命周期状态迁移错误
+var (
+	ErrEngineRunning    = errors.New("engine is already running")
+	ErrEngineStarting   = errors.New("engine is starting")
+	ErrEngineStopping   = errors.New("engine is stopping")
+	ErrEngineNotRunning = errors.New("engine is not running")
+)
+
+// 返回引擎当前的生命周期状态
+func (e *Engine) State() EngineState {
+	return EngineState(e.state.Load())
+}
+
+// 返回服务器的运行状态
+func (e *Engine) IsRunning() bool {
+	return e.State() == EngineStateRunning
+}
+
+// 更新引擎的生命周期状态，调用方需持有 stateMu
+func (e *Engine) setState(state EngineState) {
+	e.state.Store(int32(state))
+}
+
+// 重新构建 Gin 引擎并注册内置服务
+// 重启时使用全新的路由树，避免中间件和路由被重复注册
+func (e *Engine) rebuildGinEngines() error {
+	e.routeMetas = nil
+	if err := e.initGinEngine(e.opts); err != nil {
+		return err
+	}
+	e.adminSvr, e.adminRoot = nil, nil
+	e.initAdminEngine()
+	return e.registerBuiltinServices()
+}
@@ -0,0 +1,253 @@
+package orbit
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	com "github.com/shengyanli1982/orbit/common"
+	mtc "github.com/shengyanli1982/orbit/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type whoService struct {
+	name string
+	once sync.Once
+	quit chan struct{}
+}
+
+func (s *whoService) RegisterGroup(g *gin.RouterGroup) {
+	g.GET("/who", func(c *gin.Context) {
+		c.String(http.StatusOK, s.name)
+	})
+	g.POST("/quit", func(c *gin.Context) {
+		s.once.Do(func() { close(s.quit) })
+		c.Status(http.StatusOK)
+	})
+}
+
+func getWho(t *testing.T, endpoint string) string {
+	t.Helper()
+
+	resp, err := http.Get("http://" + endpoint + "/who")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestEngineStateString(t *testing.T) {
+	assert.Equal(t, "new", EngineStateNew.String())
+	assert.Equal(t, "running", EngineStateRunning.String())
+	assert.Equal(t, "stopping", EngineStateStopping.String())
+	assert.Equal(t, "stopped", EngineStateStopped.String())
+	assert.Equal(t, "unknown", EngineState(100).String())
+}
+
+func TestEngineRestart(t *testing.T) {
+	config := NewConfig().WithRelease().WithEphemeralPort().WithPrometheusRegistry(prometheus.NewRegistry())
+	engine := NewEngine(config, ReleaseOptions())
+	assert.Equal(t, EngineStateNew, engine.State())
+
+	var calls atomic.Int32
+	engine.RegisterMiddleware(func(c *gin.Context) {
+		calls.Add(1)
+		c.Next()
+	})
+	engine.RegisterService(&whoService{name: "restart", quit: make(chan struct{})})
+
+	for i := 0; i < 3; i++ {
+		engine.Run()
+		require.Equal(t, EngineStateRunning, engine.State())
+
+		select {
+		case <-engine.Ready():
+		default:
+			t.Fatal("ready channel not closed while running")
+		}
+
+		// 每次请求只经过一次用户中间件，路由不会重复注册
+		calls.Store(0)
+		endpoint := "http://" + engine.GetListenEndpoint()
+		assert.Equal(t, "restart", getWho(t, engine.GetListenEndpoint()))
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Equal(t, com.RequestOK, getHealthCheck(t, http.DefaultClient, endpoint))
+
+		require.NoError(t, engine.Shutdown(context.Background()))
+		assert.Equal(t, EngineStateStopped, engine.State())
+
+		select {
+		case <-engine.Ready():
+			t.Fatal("ready channel closed after engine stopped")
+		default:
+		}
+	}
+}
+
+func TestEngineInvalidTransitions(t *testing.T) {
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), NewOptions())
+
+	// 未启动的引擎不能停止
+	assert.ErrorIs(t, engine.Shutdown(context.Background()), ErrEngineNotRunning)
+	engine.Stop()
+	assert.Equal(t, EngineStateNew, engine.State())
+
+	engine.Run()
+	assert.ErrorIs(t, engine.start(context.Background()), ErrEngineRunning)
+
+	require.NoError(t, engine.Shutdown(context.Background()))
+	assert.ErrorIs(t, engine.Shutdown(context.Background()), ErrEngineNotRunning)
+
+	// 停止过程中不能启动
+	engine.stateMu.Lock()
+	engine.setState(EngineStateStopping)
+	engine.stateMu.Unlock()
+	assert.ErrorIs(t, engine.start(context.Background()), ErrEngineStopping)
+}
+
+func TestEngineConcurrentShutdownWaitsForCompletion(t *testing.T) {
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), NewOptions())
+
+	done := make(chan error, 1)
+	go func() { done <- engine.RunContext(context.Background()) }()
+	<-engine.Ready()
+
+	engine.Stop()
+	assert.Equal(t, EngineStateStopped, engine.State())
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunContext did not return after engine stopped")
+	}
+}
+
+func TestEngineRestartWithInjectedListenerFails(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	engine := NewEngine(NewConfig().WithRelease().WithListener(l), NewOptions())
+	require.NoError(t, engine.start(context.Background()))
+	engine.Stop()
+
+	assert.ErrorIs(t, engine.start(context.Background()), errInjectedListenerClosed)
+	assert.Equal(t, EngineStateStopped, engine.State())
+}
+
+func TestEngineShutdownDrainsBeforeStopping(t *testing.T) {
+	config := NewConfig().WithRelease().WithEphemeralPort().WithPreStopDelay(300)
+	engine := NewEngine(config, NewOptions())
+	engine.Run()
+	require.True(t, engine.IsRunning())
+	url := "http://" + engine.GetListenEndpoint() + com.HealthCheckURLPath
+
+	done := make(chan error, 1)
+	go func() { done <- engine.Shutdown(context.Background()) }()
+
+	// 等待期间服务仍可访问，但健康检查返回 503
+	require.Eventually(t, engine.IsDraining, time.Second, 5*time.Millisecond)
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, EngineStateStopping, engine.State())
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not finish")
+	}
+	assert.Equal(t, EngineStateStopped, engine.State())
+
+	// 再次启动后恢复就绪
+	engine.Run()
+	defer engine.Stop()
+	assert.False(t, engine.IsDraining())
+	assert.Equal(t, com.RequestOK, getHealthCheck(t, http.DefaultClient, "http://"+engine.GetListenEndpoint()))
+}
+
+func TestEngineShutdownForceClosesAfterTimeout(t *testing.T) {
+	config := NewConfig().WithRelease().WithEphemeralPort().WithShutdownTimeout(100)
+	engine := NewEngine(config, NewOptions())
+
+	release := make(chan struct{})
+	defer close(release)
+	engine.RegisterService(NewHttpService(func(g *gin.RouterGroup) {
+		g.GET("/slow", func(c *gin.Context) {
+			<-release
+			c.Status(http.StatusOK)
+		})
+	}))
+	engine.Run()
+	require.True(t, engine.IsRunning())
+
+	reqErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + engine.GetListenEndpoint() + "/slow")
+		if err == nil {
+			resp.Body.Close()
+		}
+		reqErr <- err
+	}()
+	require.Eventually(t, func() bool { return engine.inflight.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	// 超时后强制关闭连接，进行中的请求被中断
+	start := time.Now()
+	err := engine.Shutdown(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, EngineStateStopped, engine.State())
+
+	select {
+	case err := <-reqErr:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("in-flight request was not cut off")
+	}
+}
+
+func TestEngineRetryStartAfterPortCollision(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, port, err := net.SplitHostPort(occupied.Addr().String())
+	require.NoError(t, err)
+
+	registry := prometheus.NewRegistry()
+	config := NewConfig().WithRelease().WithAddress("127.0.0.1").WithPort(parsePort(t, port)).WithPrometheusRegistry(registry)
+	engine := NewEngine(config, NewOptions().EnableMetric())
+	require.NoError(t, engine.initErr)
+
+	// 端口被占用时启动失败，度量标准随之注销
+	assert.Error(t, engine.RunContext(context.Background()))
+	assert.Equal(t, EngineStateNew, engine.State())
+	probe := mtc.NewServerMetrics(registry)
+	require.NoError(t, probe.Register())
+	probe.Unregister()
+
+	// 端口释放后可以再次启动
+	require.NoError(t, occupied.Close())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- engine.RunContext(ctx) }()
+	select {
+	case <-engine.Ready():
+	case err := <-done:
+		t.Fatalf("engine failed to start again: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("engine did not become ready")
+	}
+	assert.Error(t, mtc.NewServerMetrics(registry).Register())
+
+	cancel()
+	assert.NoError(t, <-done)
+}
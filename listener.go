@@ -0,0 +1,84 @@
+package orbit
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/shengyanli1982/orbit/internal/listener"
+)
+
+// 注入的监听器只能使用一次
+var errInjectedListenerClosed = errors.New("injected listener has been closed by a previous run")
+
+// 创建业务端与管理端的监听器
+// 任一监听器创建失败时，关闭已创建的监听器并返回错误
+func (e *Engine) createListeners() error {
+	l, err := e.listenPublic()
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", e.endpoint, err)
+	}
+
+	var adminListener net.Listener
+	if e.adminSvr != nil {
+		if adminListener = listener.TakeInherited(adminServerName, e.adminEndpoint); adminListener != nil {
+			e.config.logger.Info("use inherited listener", "server", adminServerName, "address", listenerAddress(adminListener))
+		} else if adminListener, err = net.Listen("tcp", e.adminEndpoint); err != nil {
+			_ = l.Close()
+			return fmt.Errorf("failed to listen on admin %s: %w", e.adminEndpoint, err)
+		}
+	}
+
+	e.listener = l
+	e.adminListener = adminListener
+	return nil
+}
+
+// 创建业务端监听器
+// 优先级：外部注入的监听器 > 从父进程继承的监听器（LISTEN_FDS）> Unix 域套接字 > TCP 地址
+func (e *Engine) listenPublic() (net.Listener, error) {
+	if e.config.listener != nil {
+		// 注入的监听器在引擎停止时已被关闭，无法用于再次启动
+		if e.injectedUsed {
+			return nil, errInjectedListenerClosed
+		}
+		e.injectedUsed = true
+		return e.config.listener, nil
+	}
+
+	if l := listener.TakeInherited(httpServerName, e.endpoint); l != nil {
+		e.config.logger.Info("use inherited listener", "server", httpServerName, "address", listenerAddress(l))
+		return l, nil
+	}
+
+	if e.config.UnixSocket != "" {
+		return listener.ListenUnix(e.config.UnixSocket, listener.UnixSocketOptions{
+			Mode:  os.FileMode(e.config.UnixSocketMode),
+			User:  e.config.UnixSocketUser,
+			Group: e.config.UnixSocketGroup,
+		})
+	}
+
+	return net.Listen("tcp", e.endpoint)
+}
+
+// 根据配置生成业务端的监听地址
+func configuredEndpoint(config *Config) string {
+	if config.listener != nil {
+		return listenerAddress(config.listener)
+	}
+	if config.UnixSocket != "" {
+		return config.UnixSocket
+	}
+	return net.JoinHostPort(config.Address, strconv.Itoa(int(config.Port)))
+}
+
+// 返回监听器实际绑定的地址
+func listenerAddress(l net.Listener) string {
+	if l == nil {
+		return ""
+	}
+	return l.Addr().String()
+}
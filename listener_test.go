@@ -0,0 +1,120 @@
+package orbit
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 通过指定的 http.Client 请求健康检查接口
+func getHealthCheck(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+
+	resp, err := client.Get(url + com.HealthCheckURLPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	return string(body)
+}
+
+func TestEngineListenOnEphemeralPort(t *testing.T) {
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), NewOptions())
+	assert.Equal(t, "127.0.0.1:0", engine.GetListenEndpoint())
+
+	engine.Run()
+	defer engine.Stop()
+	require.True(t, engine.IsRunning())
+
+	endpoint := engine.GetListenEndpoint()
+	_, port, err := net.SplitHostPort(endpoint)
+	require.NoError(t, err)
+	assert.NotEqual(t, "0", port)
+
+	assert.Equal(t, com.RequestOK, getHealthCheck(t, http.DefaultClient, "http://"+endpoint))
+}
+
+func TestEngineServeOnInjectedListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	engine := NewEngine(NewConfig().WithRelease().WithListener(l), NewOptions())
+	assert.Equal(t, l.Addr().String(), engine.GetListenEndpoint())
+
+	engine.Run()
+	require.True(t, engine.IsRunning())
+	assert.Equal(t, l.Addr().String(), engine.GetListenEndpoint())
+	assert.Equal(t, com.RequestOK, getHealthCheck(t, http.DefaultClient, "http://"+l.Addr().String()))
+
+	// 引擎停止后会关闭注入的监听器
+	engine.Stop()
+	_, err = net.Dial("tcp", l.Addr().String())
+	assert.Error(t, err)
+}
+
+func TestEngineServeOnUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix socket file mode is not supported on windows")
+	}
+
+	path := filepath.Join(t.TempDir(), "orbit.sock")
+	engine := NewEngine(NewConfig().WithRelease().WithUnixSocket(path, 0o660, "", ""), NewOptions())
+	engine.Run()
+	defer engine.Stop()
+	require.True(t, engine.IsRunning())
+	assert.Equal(t, path, engine.GetListenEndpoint())
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+		Timeout: time.Second,
+	}
+	assert.Equal(t, com.RequestOK, getHealthCheck(t, client, "http://unix"))
+}
+
+func TestEngineRunFailsWhenAddressInUse(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	config := NewConfig().WithRelease()
+	config.Port = parsePort(t, port)
+	engine := NewEngine(config, NewOptions())
+
+	engine.Run()
+	assert.False(t, engine.IsRunning())
+	assert.Error(t, engine.GetRunError())
+	assert.Nil(t, engine.httpSvr)
+
+	// RunContext 直接返回绑定失败的错误，且不会关闭就绪通道
+	assert.Error(t, engine.RunContext(context.Background()))
+	select {
+	case <-engine.Ready():
+		t.Fatal("ready channel closed on startup failure")
+	default:
+	}
+}
+
+// 将端口字符串转换为 uint16
+func parsePort(t *testing.T, port string) uint16 {
+	t.Helper()
+	addr, err := net.ResolveTCPAddr("tcp", "127.0.0.1:"+port)
+	require.NoError(t, err)
+	return uint16(addr.Port)
+}
@@ -0,0 +1,99 @@
+package orbit
+
+import (
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/shengyanli1982/orbit/internal/loader"
+)
+
+// 默认的环境变量前缀
+const defaultEnvPrefix = "ORBIT"
+
+// 配置文件的结构：Config 的字段位于顶层，功能开关位于 features 下
+type loaderSchema struct {
+	*Config
+	Features *FeatureOptions `json:"features,omitempty" yaml:"features,omitempty"`
+}
+
+// Loader 从配置文件、环境变量和显式覆盖值构建 Config 和 Options
+//
+// 优先级从低到高依次为：
+//  1. 默认值（NewConfig、NewOptions）
+//  2. 配置文件，格式由扩展名决定：.yaml、.yml、.json、.toml
+//  3. 环境变量，例如 ORBIT_PORT、ORBIT_TLS_CERT_FILE、ORBIT_FEATURES_METRIC，切片使用逗号分隔
+//  4. 通过 WithOverride 设置的覆盖值
+//
+// 字段名与 Config 的 json 标签一致，功能开关位于 features 下，嵌套字段使用 "." 分隔，例如 tls.certFile
+type Loader struct {
+	file      string
+	envPrefix string
+	overrides map[string]any
+}
+
+// 创建一个新的配置加载器
+func NewLoader() *Loader {
+	return &Loader{envPrefix: defaultEnvPrefix, overrides: make(map[string]any)}
+}
+
+// 设置配置文件路径，为空时不读取配置文件
+func (l *Loader) WithFile(path string) *Loader {
+	l.file = path
+	return l
+}
+
+// 设置环境变量前缀，为空时不读取环境变量
+func (l *Loader) WithEnvPrefix(prefix string) *Loader {
+	l.envPrefix = strings.TrimSuffix(prefix, "_")
+	return l
+}
+
+// 设置覆盖值，key 为以 "." 分隔的字段路径，例如 port、tls.certFile、features.metric
+func (l *Loader) WithOverride(key string, value any) *Loader {
+	l.overrides[key] = value
+	return l
+}
+
+// 按优先级合并各个来源的配置，返回 Config 和 Options
+func (l *Loader) Load() (*Config, *Options, error) {
+	features := NewOptions().Features()
+	values, err := loader.Encode(&loaderSchema{Config: NewConfig(), Features: &features})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if l.file != "" {
+		fileValues, err := loader.ReadFile(l.file)
+		if err != nil {
+			return nil, nil, err
+		}
+		values = loader.Merge(values, fileValues)
+	}
+
+	if l.envPrefix != "" {
+		envValues, err := loader.FromEnv(l.envPrefix, os.Environ(), reflect.TypeOf(loaderSchema{}))
+		if err != nil {
+			return nil, nil, err
+		}
+		values = loader.Merge(values, envValues)
+	}
+
+	overrides := make(map[string]any)
+	for key, value := range l.overrides {
+		loader.Set(overrides, key, value)
+	}
+	values = loader.Merge(values, overrides)
+
+	// 解码到默认配置上，保留日志记录器、Prometheus 注册表等不可序列化的字段
+	schema := loaderSchema{Config: NewConfig(), Features: &FeatureOptions{}}
+	if err := loader.Decode(values, &schema); err != nil {
+		return nil, nil, err
+	}
+	return schema.Config, schema.Features.Options(), nil
+}
+
+// 从配置文件和 ORBIT_* 环境变量加载 Config 和 Options
+func LoadConfig(path string) (*Config, *Options, error) {
+	return NewLoader().WithFile(path).Load()
+}
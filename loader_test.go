@@ -0,0 +1,149 @@
+package orbit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 写入测试用的配置文件
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoaderDefaults(t *testing.T) {
+	config, opts, err := NewLoader().WithEnvPrefix("").Load()
+	require.NoError(t, err)
+
+	expected := NewConfig()
+	assert.Equal(t, expected.Address, config.Address)
+	assert.Equal(t, expected.Port, config.Port)
+	assert.Equal(t, expected.TrustedProxies, config.TrustedProxies)
+	assert.Equal(t, expected.CORSPolicy, config.CORSPolicy)
+	assert.Equal(t, expected.logger, config.logger)
+	assert.Equal(t, NewOptions(), opts)
+}
+
+func TestLoaderPrecedence(t *testing.T) {
+	path := writeConfigFile(t, "orbit.yaml", `
+address: 0.0.0.0
+port: 9000
+httpReadTimeout: 3000
+trustedProxies: ["10.0.0.0/8"]
+tls:
+  certFile: /etc/orbit/tls.crt
+  keyFile: /etc/orbit/tls.key
+corsPolicy:
+  enabled: false
+features:
+  metric: true
+  pprof: true
+`)
+	t.Setenv("ORBIT_PORT", "9100")
+	t.Setenv("ORBIT_TLS_KEY_FILE", "/run/secrets/tls.key")
+	t.Setenv("ORBIT_TRUSTED_PROXIES", "10.1.0.0/16,10.2.0.0/16")
+	t.Setenv("ORBIT_FEATURES_PPROF", "false")
+
+	config, opts, err := NewLoader().
+		WithFile(path).
+		WithOverride("port", 9200).
+		WithOverride("features.swagger", true).
+		Load()
+	require.NoError(t, err)
+
+	// 默认值 < 配置文件 < 环境变量 < 覆盖值
+	assert.Equal(t, "0.0.0.0", config.Address)
+	assert.Equal(t, uint16(9200), config.Port)
+	assert.Equal(t, uint32(3000), config.HttpReadTimeout)
+	assert.Equal(t, com.DefaultHttpIdleTimeoutMillis, config.HttpWriteTimeout)
+	assert.Equal(t, []string{"10.1.0.0/16", "10.2.0.0/16"}, config.TrustedProxies)
+	assert.Equal(t, "/etc/orbit/tls.crt", config.TLS.CertFile)
+	assert.Equal(t, "/run/secrets/tls.key", config.TLS.KeyFile)
+	assert.False(t, config.CORSPolicy.Enabled)
+
+	features := opts.Features()
+	assert.True(t, features.HealthCheck)
+	assert.True(t, features.Metric)
+	assert.False(t, features.PProf)
+	assert.True(t, features.Swagger)
+}
+
+func TestLoaderFileFormats(t *testing.T) {
+	files := map[string]string{
+		"orbit.json": `{"port": 9300, "tls": {"certFile": "/a.crt"}, "features": {"metric": true}}`,
+		"orbit.toml": "port = 9300\n[tls]\ncertFile = \"/a.crt\"\n[features]\nmetric = true\n",
+		"orbit.yml":  "port: 9300\ntls:\n  certFile: /a.crt\nfeatures:\n  metric: true\n",
+	}
+	for name, content := range files {
+		config, opts, err := LoadConfig(writeConfigFile(t, name, content))
+		require.NoError(t, err, name)
+		assert.Equal(t, uint16(9300), config.Port, name)
+		assert.Equal(t, "/a.crt", config.TLS.CertFile, name)
+		assert.True(t, opts.metric, name)
+	}
+}
+
+func TestLoaderErrors(t *testing.T) {
+	_, _, err := LoadConfig(writeConfigFile(t, "orbit.yaml", "prot: 9000\n"))
+	assert.Error(t, err)
+
+	_, _, err = LoadConfig(writeConfigFile(t, "orbit.conf", "port: 9000\n"))
+	assert.ErrorContains(t, err, "unsupported config file format")
+
+	t.Setenv("ORBIT_PORT", "not-a-port")
+	_, _, err = NewLoader().Load()
+	assert.ErrorContains(t, err, "ORBIT_PORT")
+}
+
+func TestLoaderAccessLogPolicy(t *testing.T) {
+	path := writeConfigFile(t, "orbit.yaml", `
+accessLogPolicy:
+  logClientErrors: true
+  slowThreshold: 500
+  sampleRate: 0.1
+  routeSampleRates:
+    /users/:id: 0.01
+  skipPaths: ["/internal/*"]
+`)
+	t.Setenv("ORBIT_ACCESS_LOG_POLICY_SKIP_METHODS", "OPTIONS,HEAD")
+
+	config, _, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, &com.AccessLogPolicy{
+		LogClientErrors:  true,
+		SlowThreshold:    500,
+		SampleRate:       com.SampleRate(0.1),
+		RouteSampleRates: map[string]float64{"/users/:id": 0.01},
+		SkipPaths:        []string{"/internal/*"},
+		SkipMethods:      []string{"OPTIONS", "HEAD"},
+	}, config.AccessLogPolicy)
+
+	// 显式设置为 0 的采样比例与未设置区分开
+	config, _, err = LoadConfig(writeConfigFile(t, "orbit.yaml", "accessLogPolicy:\n  sampleRate: 0\n"))
+	require.NoError(t, err)
+	require.NotNil(t, config.AccessLogPolicy.SampleRate)
+	assert.Zero(t, *config.AccessLogPolicy.SampleRate)
+}
+
+func TestLoaderCustomEnvPrefix(t *testing.T) {
+	t.Setenv("MYAPP_ADDRESS", "0.0.0.0")
+	t.Setenv("ORBIT_ADDRESS", "10.0.0.1")
+
+	config, _, err := NewLoader().WithEnvPrefix("MYAPP_").Load()
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0", config.Address)
+}
+
+func TestOptionsFeaturesRoundTrip(t *testing.T) {
+	opts := DebugOptions().EnableSignalHandling().EnableStrictConfig().EnableDebugRoutes().EnableRequestID()
+	features := opts.Features()
+	assert.Equal(t, opts, features.Options())
+}
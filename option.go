@@ -10,6 +10,10 @@ type Options struct {
 	fixedPath         bool // 启用固定路径重定向
 	forwordByClientIp bool // 启用客户端 IP 转发
 	recReqBody        bool // 启用请求体记录
+	signal            bool // 启用系统信号处理
+	strictConfig      bool // 启用严格配置校验
+	routes            bool // 启用路由表端点
+	requestID         bool // 启用请求 ID 中间件
 }
 
 // NewOptions 创建一个新的 Options 实例
@@ -65,6 +69,85 @@ func (o *Options) EnableRecordRequestBody() *Options {
 	return o
 }
 
+// EnableSignalHandling 启用系统信号处理
+// SIGINT/SIGTERM 触发优雅关闭，再次收到终止信号时强制退出，SIGHUP 触发通过 RegisterReloadFunc 注册的重载函数
+func (o *Options) EnableSignalHandling() *Options {
+	o.signal = true
+	return o
+}
+
+// EnableStrictConfig 启用严格配置校验
+// 创建引擎时调用 Config.Validate，配置存在任何问题时引擎拒绝启动
+func (o *Options) EnableStrictConfig() *Options {
+	o.strictConfig = true
+	return o
+}
+
+// EnableDebugRoutes 启用路由表端点 /debug/routes，列出所有已注册的路由
+func (o *Options) EnableDebugRoutes() *Options {
+	o.routes = true
+	return o
+}
+
+// EnableRequestID 启用请求 ID 中间件
+// 为缺少或携带不合法 X-Request-Id 的请求生成请求 ID，并在响应头中返回
+func (o *Options) EnableRequestID() *Options {
+	o.requestID = true
+	return o
+}
+
+// FeatureOptions 是 Options 的可序列化形式，用于从配置文件和环境变量加载功能开关
+type FeatureOptions struct {
+	HealthCheck           bool `json:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`                     // 启用健康检查
+	PProf                 bool `json:"pprof,omitempty" yaml:"pprof,omitempty"`                                 // 启用 pprof 端点
+	Swagger               bool `json:"swagger,omitempty" yaml:"swagger,omitempty"`                             // 启用 swagger 文档
+	Metric                bool `json:"metric,omitempty" yaml:"metric,omitempty"`                               // 启用度量收集
+	RedirectTrailingSlash bool `json:"redirectTrailingSlash,omitempty" yaml:"redirectTrailingSlash,omitempty"` // 启用尾部斜杠重定向
+	RedirectFixedPath     bool `json:"redirectFixedPath,omitempty" yaml:"redirectFixedPath,omitempty"`         // 启用固定路径重定向
+	ForwardedByClientIp   bool `json:"forwardedByClientIp,omitempty" yaml:"forwardedByClientIp,omitempty"`     // 启用客户端 IP 转发
+	RecordRequestBody     bool `json:"recordRequestBody,omitempty" yaml:"recordRequestBody,omitempty"`         // 启用请求体记录
+	SignalHandling        bool `json:"signalHandling,omitempty" yaml:"signalHandling,omitempty"`               // 启用系统信号处理
+	StrictConfig          bool `json:"strictConfig,omitempty" yaml:"strictConfig,omitempty"`                   // 启用严格配置校验
+	DebugRoutes           bool `json:"debugRoutes,omitempty" yaml:"debugRoutes,omitempty"`                     // 启用路由表端点
+	RequestID             bool `json:"requestId,omitempty" yaml:"requestId,omitempty"`                         // 启用请求 ID 中间件
+}
+
+// Options 根据功能开关创建 Options 实例
+func (f *FeatureOptions) Options() *Options {
+	return &Options{
+		healthCheck:       f.HealthCheck,
+		pprof:             f.PProf,
+		swagger:           f.Swagger,
+		metric:            f.Metric,
+		trailingSlash:     f.RedirectTrailingSlash,
+		fixedPath:         f.RedirectFixedPath,
+		forwordByClientIp: f.ForwardedByClientIp,
+		recReqBody:        f.RecordRequestBody,
+		signal:            f.SignalHandling,
+		strictConfig:      f.StrictConfig,
+		routes:            f.DebugRoutes,
+		requestID:         f.RequestID,
+	}
+}
+
+// Features 返回 Options 对应的功能开关
+func (o *Options) Features() FeatureOptions {
+	return FeatureOptions{
+		HealthCheck:           o.healthCheck,
+		PProf:                 o.pprof,
+		Swagger:               o.swagger,
+		Metric:                o.metric,
+		RedirectTrailingSlash: o.trailingSlash,
+		RedirectFixedPath:     o.fixedPath,
+		ForwardedByClientIp:   o.forwordByClientIp,
+		RecordRequestBody:     o.recReqBody,
+		SignalHandling:        o.signal,
+		StrictConfig:          o.strictConfig,
+		DebugRoutes:           o.routes,
+		RequestID:             o.requestID,
+	}
+}
+
 // DebugOptions 返回一个启用了 pprof、swagger、metric 和请求体记录功能的 Options 实例，用于调试环境
 func DebugOptions() *Options {
 	return NewOptions().EnablePProf().EnableSwagger().EnableMetric().EnableRecordRequestBody()
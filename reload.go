@@ -0,0 +1,192 @@
+package orbit
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shengyanli1982/orbit/internal/loader"
+	mid "github.com/shengyanli1982/orbit/internal/middleware"
+	"go.uber.org/zap/zapcore"
+)
+
+// 自定义的 logr 日志记录器无法调整日志级别
+var errLogLevelUnsupported = errors.New("log level can not be changed for a custom logr logger, use Config.WithZapLogger instead")
+
+// 可以在运行时热更新的配置字段（json 字段名）
+var liveConfigFields = map[string]struct{}{
+	"corsPolicy":      {},
+	"accessLogPolicy": {},
+	"trustedProxies":  {},
+	"remoteIPHeaders": {},
+	"logLevel":        {},
+}
+
+// 只在启用客户端 IP 转发时生效的热更新字段（json 字段名）
+var clientIPConfigFields = map[string]struct{}{
+	"trustedProxies":  {},
+	"remoteIPHeaders": {},
+}
+
+// 运行时配置快照，创建后不可修改
+// 每个请求开始时获取一次，保证同一请求内看到一致的配置
+type liveConfig struct {
+	cors      gin.HandlerFunc      // CORS 中间件
+	clientIP  *mid.ClientIPPolicy  // 真实客户端 IP 解析策略（未启用客户端 IP 转发时为 nil）
+	accessLog *mid.AccessLogFilter // 访问日志过滤器（未配置访问日志策略时为 nil）
+}
+
+// 根据配置创建运行时配置快照
+func newLiveConfig(config *Config, forwarded bool) (*liveConfig, error) {
+	live := &liveConfig{
+		cors:      mid.CorsWithPolicy(*config.CORSPolicy),
+		accessLog: mid.NewAccessLogFilter(config.AccessLogPolicy),
+	}
+	if forwarded {
+		policy, err := mid.NewClientIPPolicy(config.TrustedProxies, config.RemoteIPHeaders)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set trusted proxies %v: %w", config.TrustedProxies, err)
+		}
+		live.clientIP = policy
+	}
+	return live, nil
+}
+
+// 按当前运行时配置处理跨域请求
+func (e *Engine) corsHandler(context *gin.Context) {
+	e.live.Load().cors(context)
+}
+
+// 返回当前运行时配置中的真实客户端 IP 解析策略
+func (e *Engine) clientIPPolicy() *mid.ClientIPPolicy {
+	return e.live.Load().clientIP
+}
+
+// 返回当前运行时配置中的访问日志过滤器
+func (e *Engine) accessLogFilter() *mid.AccessLogFilter {
+	return e.live.Load().accessLog
+}
+
+// 设置日志级别，级别为空时保持当前级别
+func (e *Engine) applyLogLevel(level string) error {
+	if level == "" {
+		return nil
+	}
+	lvl, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("%w: logLevel %q is not a valid level", ErrInvalidConfig, level)
+	}
+	if e.config.levelLogger == nil {
+		return errLogLevelUnsupported
+	}
+	e.config.levelLogger.SetLevel(lvl)
+	return nil
+}
+
+// ApplyConfig 将新配置应用到引擎，引擎运行中也可以调用
+// CORS 策略、访问日志策略、可信代理、客户端 IP 解析头和日志级别立即生效，正在处理的请求继续使用旧配置
+// 其他字段的变化需要重启引擎才能生效，只记录日志，也不参与校验
+// 可热更新的字段校验失败时不做任何修改并返回汇总的错误
+func (e *Engine) ApplyConfig(config *Config) error {
+	if config == nil {
+		return fmt.Errorf("%w: config is nil", ErrInvalidConfig)
+	}
+	config = isConfigValid(config)
+	if err := config.validateLive(); err != nil {
+		return err
+	}
+	live, err := newLiveConfig(config, e.opts.forwordByClientIp)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	e.stateMu.Lock()
+	defer e.stateMu.Unlock()
+
+	changed, ignored, err := diffConfig(e.config, config)
+	if err != nil {
+		return err
+	}
+
+	// 原子替换运行时配置快照，并同步到引擎配置，重启时沿用新配置
+	e.live.Store(live)
+	e.config.CORSPolicy = cloneCORSPolicyPtr(config.CORSPolicy)
+	e.config.AccessLogPolicy = cloneAccessLogPolicyPtr(config.AccessLogPolicy)
+	e.config.TrustedProxies = cloneStringSlice(config.TrustedProxies)
+	e.config.RemoteIPHeaders = cloneStringSlice(config.RemoteIPHeaders)
+	if err := e.applyLogLevel(config.LogLevel); err != nil {
+		e.config.logger.Error(err, "failed to set log level, keep current level", "level", config.LogLevel)
+	} else {
+		e.config.LogLevel = config.LogLevel
+	}
+
+	// 未启用客户端 IP 转发时，可信代理和客户端 IP 解析头的变化不会生效
+	var ineffective []configChange
+	if !e.opts.forwordByClientIp {
+		effective := changed[:0]
+		for _, change := range changed {
+			if _, ok := clientIPConfigFields[change.field]; ok {
+				ineffective = append(ineffective, change)
+			} else {
+				effective = append(effective, change)
+			}
+		}
+		changed = effective
+	}
+
+	for _, change := range changed {
+		e.config.logger.Info("config changed", "field", change.field, "old", change.old, "new", change.new)
+	}
+	for _, change := range ineffective {
+		e.config.logger.Info("config change has no effect, client IP forwarding is disabled", "field", change.field, "old", change.old, "new", change.new)
+	}
+	for _, change := range ignored {
+		e.config.logger.Info("config change requires restart, ignored", "field", change.field, "old", change.old, "new", change.new)
+	}
+	e.config.logger.Info("config applied", "changed", len(changed), "ineffective", len(ineffective), "ignored", len(ignored))
+	return nil
+}
+
+// 配置字段的变化
+type configChange struct {
+	field    string
+	old, new any
+}
+
+// 比较新旧配置，按字段名排序返回可热更新与需要重启才能生效的变化
+func diffConfig(current, next *Config) (changed, ignored []configChange, err error) {
+	oldValues, err := loader.Encode(current)
+	if err != nil {
+		return nil, nil, err
+	}
+	newValues, err := loader.Encode(next)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fields := make([]string, 0, len(oldValues)+len(newValues))
+	for field := range oldValues {
+		fields = append(fields, field)
+	}
+	for field := range newValues {
+		if _, ok := oldValues[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		if reflect.DeepEqual(oldValues[field], newValues[field]) {
+			continue
+		}
+		change := configChange{field: field, old: oldValues[field], new: newValues[field]}
+		if _, ok := liveConfigFields[field]; ok {
+			changed = append(changed, change)
+		} else {
+			ignored = append(ignored, change)
+		}
+	}
+	return changed, ignored, nil
+}
@@ -0,0 +1,212 @@
+package orbit
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+	ulog "github.com/shengyanli1982/orbit/utils/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// 创建一个写入缓冲区的 Zap 日志记录器
+func newBufferZapLogger() (*ulog.ZapLogger, *syncBuffer) {
+	buf := &syncBuffer{}
+	return ulog.NewZapLogger(zapcore.AddSync(buf), false), buf
+}
+
+// 并发安全的缓冲区
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestApplyConfigSwapsCORSPolicy(t *testing.T) {
+	engine := NewEngine(NewConfig(), NewOptions())
+	require.NoError(t, engine.initErr)
+	engine.GetGinEngine().GET("/cors", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/cors", nil)
+		req.Header.Set("Origin", "https://b.example.com")
+		recorder := httptest.NewRecorder()
+		engine.GetGinEngine().ServeHTTP(recorder, req)
+		return recorder
+	}
+	assert.Equal(t, "*", request().Header().Get("Access-Control-Allow-Origin"))
+
+	policy := *NewConfig().CORSPolicy
+	policy.AllowAllOrigins = false
+	policy.AllowedOrigins = []string{"https://a.example.com"}
+	require.NoError(t, engine.ApplyConfig(NewConfig().WithCORSPolicy(policy)))
+
+	assert.Empty(t, request().Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, []string{"https://a.example.com"}, engine.config.CORSPolicy.AllowedOrigins)
+}
+
+func TestApplyConfigSwapsAccessLogPolicy(t *testing.T) {
+	logger, buf := newBufferZapLogger()
+	engine := NewEngine(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort().WithZapLogger(logger), NewOptions())
+	engine.RegisterService(&emptyBodyService{})
+	engine.Run()
+	defer engine.Stop()
+
+	request := func() {
+		engine.GetGinEngine().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/empty", nil))
+	}
+	request()
+	assert.Contains(t, buf.String(), `"path":"/empty"`)
+
+	policy := com.AccessLogPolicy{SkipPaths: []string{"/empty"}}
+	require.NoError(t, engine.ApplyConfig(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort().WithZapLogger(logger).WithAccessLogPolicy(policy)))
+	before := strings.Count(buf.String(), `"path":"/empty"`)
+	request()
+	assert.Equal(t, before, strings.Count(buf.String(), `"path":"/empty"`))
+	assert.Equal(t, []string{"/empty"}, engine.config.AccessLogPolicy.SkipPaths)
+}
+
+func TestApplyConfigSwapsTrustedProxies(t *testing.T) {
+	engine := NewEngine(NewConfig(), NewOptions().EnableForwardedByClientIp())
+	require.NoError(t, engine.initErr)
+	engine.GetGinEngine().GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+	request := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", "1.2.3.4")
+		recorder := httptest.NewRecorder()
+		engine.GetGinEngine().ServeHTTP(recorder, req)
+		return recorder.Body.String()
+	}
+	assert.Equal(t, "1.2.3.4", request())
+
+	require.NoError(t, engine.ApplyConfig(NewConfig().WithTrustedProxies([]string{"192.168.0.0/16"})))
+	assert.Equal(t, "10.0.0.1", request())
+
+	require.NoError(t, engine.ApplyConfig(NewConfig().WithTrustedProxies([]string{"10.0.0.0/8"})))
+	assert.Equal(t, "1.2.3.4", request())
+}
+
+func TestApplyConfigSetsLogLevelAndLogsDiff(t *testing.T) {
+	logger, buf := newBufferZapLogger()
+	engine := NewEngine(NewConfig().WithZapLogger(logger).WithLogLevel("info"), NewOptions())
+	require.NoError(t, engine.initErr)
+	assert.Equal(t, zapcore.InfoLevel, logger.GetLevel())
+
+	require.NoError(t, engine.ApplyConfig(NewConfig().WithZapLogger(logger).WithLogLevel("debug").WithPort(9999)))
+	assert.Equal(t, zapcore.DebugLevel, logger.GetLevel())
+	assert.Equal(t, "debug", engine.config.LogLevel)
+
+	logs := buf.String()
+	assert.Contains(t, logs, `"message":"config changed","field":"logLevel","old":"info","new":"debug"`)
+	assert.Contains(t, logs, `"message":"config change requires restart, ignored","field":"port"`)
+	assert.Equal(t, uint16(com.DefaultHttpListenPort), engine.config.Port)
+}
+
+func TestApplyConfigRejectsInvalidConfig(t *testing.T) {
+	engine := NewEngine(NewConfig(), NewOptions().EnableForwardedByClientIp())
+	require.NoError(t, engine.initErr)
+
+	err := engine.ApplyConfig(NewConfig().WithTrustedProxies([]string{"invalid-cidr"}).WithLogLevel("loud"))
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorContains(t, err, "invalid-cidr")
+	assert.ErrorContains(t, err, "loud")
+	assert.Equal(t, []string{"0.0.0.0/0", "::/0"}, engine.config.TrustedProxies)
+
+	assert.ErrorIs(t, engine.ApplyConfig(nil), ErrInvalidConfig)
+}
+
+func TestApplyConfigIgnoresInvalidRestartFields(t *testing.T) {
+	logger, buf := newBufferZapLogger()
+	engine := NewEngine(NewConfig().WithZapLogger(logger), NewOptions())
+	require.NoError(t, engine.initErr)
+
+	// 需要重启才能生效的字段不参与校验，只记录日志
+	policy := *NewConfig().CORSPolicy
+	policy.AllowAllOrigins = false
+	policy.AllowedOrigins = []string{"https://a.example.com"}
+	config := NewConfig().WithZapLogger(logger).WithMetricNamespace("bad-name", "").WithCORSPolicy(policy)
+	require.NoError(t, engine.ApplyConfig(config))
+
+	assert.Equal(t, []string{"https://a.example.com"}, engine.config.CORSPolicy.AllowedOrigins)
+	assert.Empty(t, engine.config.MetricNamespace)
+	assert.Contains(t, buf.String(), `"message":"config change requires restart, ignored","field":"metricNamespace"`)
+}
+
+func TestApplyConfigReportsIneffectiveClientIPFields(t *testing.T) {
+	logger, buf := newBufferZapLogger()
+	engine := NewEngine(NewConfig().WithZapLogger(logger), NewOptions())
+	require.NoError(t, engine.initErr)
+
+	require.NoError(t, engine.ApplyConfig(NewConfig().WithZapLogger(logger).WithTrustedProxies([]string{"10.0.0.0/8"})))
+
+	logs := buf.String()
+	assert.Contains(t, logs, `"message":"config change has no effect, client IP forwarding is disabled","field":"trustedProxies"`)
+	assert.NotContains(t, logs, `"message":"config changed","field":"trustedProxies"`)
+	assert.Contains(t, logs, `"message":"config applied","changed":0,"ineffective":1,"ignored":0`)
+}
+
+func TestApplyConfigKeepsSettingsAcrossRestart(t *testing.T) {
+	engine := NewEngine(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort(), NewOptions())
+	engine.Run()
+	engine.Stop()
+
+	policy := *NewConfig().CORSPolicy
+	policy.Enabled = false
+	require.NoError(t, engine.ApplyConfig(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort().WithCORSPolicy(policy)))
+
+	engine.Run()
+	defer engine.Stop()
+
+	req := httptest.NewRequest(http.MethodGet, com.HealthCheckURLPath, nil)
+	req.Header.Set("Origin", "https://a.example.com")
+	recorder := httptest.NewRecorder()
+	engine.GetGinEngine().ServeHTTP(recorder, req)
+	assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestApplyConfigConcurrentWithRequests(t *testing.T) {
+	engine := NewEngine(NewConfig(), NewOptions().EnableForwardedByClientIp())
+	require.NoError(t, engine.initErr)
+	engine.GetGinEngine().GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+				req.Header.Set("X-Forwarded-For", "1.2.3.4")
+				engine.GetGinEngine().ServeHTTP(httptest.NewRecorder(), req)
+			}
+		}()
+	}
+	for j := 0; j < 20; j++ {
+		proxies := []string{"0.0.0.0/0"}
+		if j%2 == 0 {
+			proxies = []string{"10.0.0.0/8"}
+		}
+		assert.NoError(t, engine.ApplyConfig(NewConfig().WithTrustedProxies(proxies)))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,271 @@
+package orbit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+)
+
+// 内置服务注册的路由所属的服务名称
+const builtinServiceName = com.OrbitName
+
+// ErrRouteConflict 表示服务注册的路由与已注册的路由冲突
+var ErrRouteConflict = errors.New("route conflict")
+
+// 从 Gin 的路由冲突信息中提取新路径与已存在的路径前缀
+var (
+	routeConflictPathRegexp   = regexp.MustCompile(`(?:new path|for path) '([^']*)'`)
+	routeConflictPrefixRegexp = regexp.MustCompile(`existing prefix '([^']*)'`)
+)
+
+// RouteConflictError 描述服务注册路由时发生的冲突，可以通过 errors.Is(err, ErrRouteConflict) 判断
+type RouteConflictError struct {
+	// 注册冲突路由的服务
+	Service string
+	// 冲突的新路由
+	Path string
+	// 已注册冲突路由的服务，无法确定时为空
+	ExistingService string
+	// 已注册的冲突路由的方法和路径，无法确定时为空
+	ExistingMethod string
+	ExistingPath   string
+	// Gin 给出的原始冲突信息
+	Reason string
+}
+
+func (e *RouteConflictError) Error() string {
+	existing := "an existing route"
+	if e.ExistingPath != "" {
+		existing = fmt.Sprintf("%s %s", e.ExistingMethod, e.ExistingPath)
+	}
+	if e.ExistingService != "" {
+		existing += " of service " + e.ExistingService
+	}
+	return fmt.Sprintf("route conflict: service %s registering %s conflicts with %s: %s", e.Service, e.Path, existing, e.Reason)
+}
+
+func (e *RouteConflictError) Is(target error) bool {
+	return target == ErrRouteConflict
+}
+
+// 路由的唯一标识
+type routeKey struct {
+	admin  bool
+	method string
+	path   string
+}
+
+// 路由注册时记录的元信息
+type routeMeta struct {
+	service     string
+	middlewares []string
+}
+
+// 执行 register 并记录其新注册路由的所属服务与完整的中间件链
+// 调用方需要持有 stateMu 或保证没有并发注册
+func (e *Engine) trackRoutes(svr *gin.Engine, service string, register func()) {
+	admin := svr == e.adminSvr && svr != nil
+	existing := make(map[routeKey]struct{})
+	for _, route := range svr.Routes() {
+		existing[routeKey{admin: admin, method: route.Method, path: route.Path}] = struct{}{}
+	}
+
+	// register 发生 panic 时也记录已成功注册的路由，便于定位冲突的另一方
+	defer func() {
+		if e.routeMetas == nil {
+			e.routeMetas = make(map[routeKey]routeMeta)
+		}
+		for _, route := range svr.Routes() {
+			key := routeKey{admin: admin, method: route.Method, path: route.Path}
+			if _, ok := existing[key]; ok {
+				continue
+			}
+			meta := routeMeta{service: service}
+			if chain := probeHandlerChain(svr, route.Method, route.Path); len(chain) > 0 {
+				meta.middlewares = chain[:len(chain)-1]
+			}
+			e.routeMetas[key] = meta
+		}
+	}()
+
+	register()
+}
+
+// 请求 context 中携带路由探测结果的键
+type routeProbeKey struct{}
+
+// 路由探测的结果
+type routeProbeResult struct {
+	fullPath string
+	handlers []string
+}
+
+// 路由探测中间件，注册在每个 Gin 引擎的最前面，因此位于所有路由处理链的第一个
+// 只处理 probeHandlerChain 发出的探测请求：记录匹配的路由和处理链后中止请求，其他请求直接放行
+func routeProbe(c *gin.Context) {
+	if result, ok := c.Request.Context().Value(routeProbeKey{}).(*routeProbeResult); ok {
+		result.fullPath = c.FullPath()
+		result.handlers = c.HandlerNames()
+		c.Abort()
+	}
+}
+
+// 向 Gin 引擎发出探测请求，返回路由完整处理链的函数名称（不含探测中间件），包括嵌套路由组和路由自身的中间件
+// 路由模板本身作为请求路径，参数与通配段按字面值匹配；没有匹配到该路由时返回 nil
+func probeHandlerChain(svr *gin.Engine, method, path string) []string {
+	result := &routeProbeResult{}
+	req := &http.Request{Method: method, URL: &url.URL{Path: path}, Header: make(http.Header)}
+	svr.ServeHTTP(discardResponseWriter{}, req.WithContext(context.WithValue(context.Background(), routeProbeKey{}, result)))
+	if result.fullPath != path || len(result.handlers) < 2 {
+		return nil
+	}
+	return result.handlers[1:]
+}
+
+// 丢弃所有写入的响应写入器，用于路由探测请求
+type discardResponseWriter struct{}
+
+func (discardResponseWriter) Header() http.Header { return http.Header{} }
+
+func (discardResponseWriter) Write(data []byte) (int, error) { return len(data), nil }
+
+func (discardResponseWriter) WriteHeader(int) {}
+
+// 注册用户服务的路由，将注册过程中的 panic 转换为错误
+// 路由冲突返回 *RouteConflictError，其他 panic 返回普通错误
+func (e *Engine) registerServiceRoutes(service Service) (err error) {
+	group, owner := e.serviceGroup(service)
+	defer func() {
+		if r := recover(); r != nil {
+			err = e.routeRegistrationError(owner, r)
+		}
+	}()
+
+	e.trackRoutes(e.ginSvr, owner, func() { service.RegisterGroup(group) })
+	return nil
+}
+
+// 根据路由注册时的 panic 信息生成错误
+func (e *Engine) routeRegistrationError(service string, r any) error {
+	reason := fmt.Sprint(r)
+	match := routeConflictPathRegexp.FindStringSubmatch(reason)
+	if match == nil {
+		return fmt.Errorf("service %s panicked while registering routes: %s", service, reason)
+	}
+
+	conflict := &RouteConflictError{Service: service, Path: match[1], Reason: reason}
+
+	// 已存在的冲突路由：路径相同，或者位于 Gin 给出的冲突前缀之下
+	prefix := conflict.Path
+	if match := routeConflictPrefixRegexp.FindStringSubmatch(reason); match != nil {
+		prefix = match[1]
+	}
+	// Gin 的冲突信息不包含方法，同一路径存在多个方法时取第一个，路径相同的路由优先
+	var existing *com.RouteInfo
+	routes := e.collectRoutes(e.ginSvr, false)
+	for i := range routes {
+		route := &routes[i]
+		if route.Path == conflict.Path {
+			existing = route
+			break
+		}
+		if existing == nil && (route.Path == prefix || strings.HasPrefix(route.Path, strings.TrimSuffix(prefix, "/")+"/")) {
+			existing = route
+		}
+	}
+	if existing != nil {
+		conflict.ExistingMethod, conflict.ExistingPath, conflict.ExistingService = existing.Method, existing.Path, existing.Service
+	}
+	return conflict
+}
+
+// 返回内置服务注册到的 Gin 引擎
+func (e *Engine) builtinServer() *gin.Engine {
+	if e.adminSvr != nil {
+		return e.adminSvr
+	}
+	return e.ginSvr
+}
+
+// Routes 返回业务端和管理端已注册的所有路由，按管理端、路径和方法排序
+// 用户服务的路由在引擎启动时注册，启动前只包含内置服务的路由
+func (e *Engine) Routes() []com.RouteInfo {
+	e.stateMu.Lock()
+	defer e.stateMu.Unlock()
+
+	routes := e.collectRoutes(e.ginSvr, false)
+	if e.adminSvr != nil {
+		routes = append(routes, e.collectRoutes(e.adminSvr, true)...)
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Admin != routes[j].Admin {
+			return !routes[i].Admin
+		}
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// 收集 Gin 引擎中的路由并补充注册时记录的元信息
+func (e *Engine) collectRoutes(svr *gin.Engine, admin bool) []com.RouteInfo {
+	if svr == nil {
+		return nil
+	}
+
+	infos := svr.Routes()
+	routes := make([]com.RouteInfo, 0, len(infos))
+	for _, info := range infos {
+		route := com.RouteInfo{Method: info.Method, Path: info.Path, Handler: info.Handler, Admin: admin}
+		if meta, ok := e.routeMetas[routeKey{admin: admin, method: info.Method, path: info.Path}]; ok {
+			route.Service = meta.service
+			route.Middlewares = cloneStringSlice(meta.middlewares)
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// 注册路由表服务，默认返回 JSON，format=text 时返回便于阅读的表格
+func (e *Engine) routesService(group *gin.RouterGroup) {
+	group.GET(com.EmptyURLPath, func(c *gin.Context) {
+		routes := e.Routes()
+		if c.Query("format") != "text" {
+			c.JSON(http.StatusOK, routes)
+			return
+		}
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(formatRoutesTable(routes)))
+	})
+}
+
+// 将路由格式化为表格
+func formatRoutesTable(routes []com.RouteInfo) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tMETHOD\tPATH\tSERVICE\tHANDLER\tMIDDLEWARES")
+	for _, route := range routes {
+		server := httpServerName
+		if route.Admin {
+			server = adminServerName
+		}
+		service := route.Service
+		if service == "" {
+			service = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", server, route.Method, route.Path, service, route.Handler, strings.Join(route.Middlewares, " -> "))
+	}
+	_ = w.Flush()
+	return sb.String()
+}
@@ -0,0 +1,249 @@
+package orbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type routesTestService struct{}
+
+func (s *routesTestService) RegisterGroup(g *gin.RouterGroup) {
+	g.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	g.POST("/users", func(c *gin.Context) { c.Status(http.StatusCreated) })
+}
+
+// 按方法和路径查找路由
+func findRoute(routes []com.RouteInfo, method, path string) (com.RouteInfo, bool) {
+	for _, route := range routes {
+		if route.Method == method && route.Path == path {
+			return route, true
+		}
+	}
+	return com.RouteInfo{}, false
+}
+
+func TestEngineRoutes(t *testing.T) {
+	engine := NewEngine(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort(), NewOptions())
+	engine.RegisterMiddleware(func(c *gin.Context) { c.Next() })
+	engine.RegisterService(&routesTestService{})
+
+	// 启动前只包含内置服务的路由
+	_, ok := findRoute(engine.Routes(), http.MethodGet, "/users/:id")
+	assert.False(t, ok)
+	ping, ok := findRoute(engine.Routes(), http.MethodGet, com.HealthCheckURLPath)
+	require.True(t, ok)
+	assert.Equal(t, builtinServiceName, ping.Service)
+
+	engine.Run()
+	defer engine.Stop()
+
+	routes := engine.Routes()
+	route, ok := findRoute(routes, http.MethodGet, "/users/:id")
+	require.True(t, ok)
+	assert.Equal(t, "*orbit.routesTestService", route.Service)
+	assert.Contains(t, route.Handler, "routesTestService")
+	assert.False(t, route.Admin)
+	require.NotEmpty(t, route.Middlewares)
+	assert.Contains(t, route.Middlewares[0], "InFlight")
+	assert.Contains(t, route.Middlewares[len(route.Middlewares)-1], "AccessLogger")
+	assert.Contains(t, route.Middlewares, "github.com/shengyanli1982/orbit.TestEngineRoutes.func1")
+
+	_, ok = findRoute(routes, http.MethodPost, "/users")
+	assert.True(t, ok)
+}
+
+// 嵌套路由组和单个路由上的中间件
+func routesTestGroupMiddleware(c *gin.Context) { c.Next() }
+
+func routesTestRouteMiddleware(c *gin.Context) { c.Next() }
+
+type nestedRoutesTestService struct{}
+
+func (s *nestedRoutesTestService) RegisterGroup(g *gin.RouterGroup) {
+	v1 := g.Group("/v1", routesTestGroupMiddleware)
+	v1.GET("/items", routesTestRouteMiddleware, func(c *gin.Context) { c.Status(http.StatusOK) })
+	v1.GET("/plain", func(c *gin.Context) { c.Status(http.StatusOK) })
+	v1.GET("/files/*path", routesTestRouteMiddleware, func(c *gin.Context) { c.Status(http.StatusOK) })
+}
+
+func TestEngineRoutesNestedGroupMiddlewares(t *testing.T) {
+	engine := NewEngine(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort(), NewOptions())
+	engine.RegisterService(&nestedRoutesTestService{})
+	engine.Run()
+	defer engine.Stop()
+
+	routes := engine.Routes()
+	items, ok := findRoute(routes, http.MethodGet, "/v1/items")
+	require.True(t, ok)
+	require.GreaterOrEqual(t, len(items.Middlewares), 2)
+	assert.Contains(t, items.Middlewares[0], "InFlight")
+	assert.Equal(t, []string{
+		"github.com/shengyanli1982/orbit.routesTestGroupMiddleware",
+		"github.com/shengyanli1982/orbit.routesTestRouteMiddleware",
+	}, items.Middlewares[len(items.Middlewares)-2:])
+	assert.Contains(t, items.Handler, "nestedRoutesTestService")
+
+	plain, ok := findRoute(routes, http.MethodGet, "/v1/plain")
+	require.True(t, ok)
+	assert.Equal(t, "github.com/shengyanli1982/orbit.routesTestGroupMiddleware", plain.Middlewares[len(plain.Middlewares)-1])
+	assert.NotContains(t, plain.Middlewares, "github.com/shengyanli1982/orbit.routesTestRouteMiddleware")
+
+	// 通配路由同样记录路由自身的中间件，探测中间件不出现在中间件链中
+	files, ok := findRoute(routes, http.MethodGet, "/v1/files/*path")
+	require.True(t, ok)
+	assert.Equal(t, "github.com/shengyanli1982/orbit.routesTestRouteMiddleware", files.Middlewares[len(files.Middlewares)-1])
+	assert.Contains(t, files.Middlewares[0], "InFlight")
+
+	// 探测不影响正常请求
+	recorder := httptest.NewRecorder()
+	engine.GetGinEngine().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/items", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestEngineRoutesAfterRestart(t *testing.T) {
+	engine := NewEngine(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort(), NewOptions())
+	engine.RegisterService(&routesTestService{})
+
+	engine.Run()
+	count := len(engine.Routes())
+	engine.Stop()
+
+	engine.Run()
+	defer engine.Stop()
+
+	routes := engine.Routes()
+	assert.Len(t, routes, count)
+	route, ok := findRoute(routes, http.MethodPost, "/users")
+	require.True(t, ok)
+	assert.Equal(t, "*orbit.routesTestService", route.Service)
+}
+
+func TestEngineRoutesAdmin(t *testing.T) {
+	engine := NewEngine(NewConfig().WithAdminPort(18090), NewOptions().EnableDebugRoutes())
+	require.NoError(t, engine.initErr)
+
+	route, ok := findRoute(engine.Routes(), http.MethodGet, com.RoutesURLPath)
+	require.True(t, ok)
+	assert.True(t, route.Admin)
+	assert.Contains(t, route.Middlewares, "github.com/shengyanli1982/orbit/internal/middleware.Recovery.func1")
+	assert.Equal(t, builtinServiceName, route.Service)
+}
+
+func TestRoutesEndpoint(t *testing.T) {
+	engine := NewEngine(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort(), NewOptions().EnableDebugRoutes())
+	engine.RegisterService(&routesTestService{})
+	engine.Run()
+	defer engine.Stop()
+
+	request := func(target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		engine.GetGinEngine().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		return recorder
+	}
+
+	recorder := request(com.RoutesURLPath)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var routes []com.RouteInfo
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &routes))
+	_, ok := findRoute(routes, http.MethodGet, "/users/:id")
+	assert.True(t, ok)
+
+	recorder = request(com.RoutesURLPath + "?format=text")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	body, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "SERVER")
+	assert.Contains(t, string(body), "/users/:id")
+	assert.Contains(t, string(body), "*orbit.routesTestService")
+}
+
+func TestRoutesEndpointDisabledByDefault(t *testing.T) {
+	engine := NewEngine(NewConfig(), NewOptions())
+	_, ok := findRoute(engine.Routes(), http.MethodGet, com.RoutesURLPath)
+	assert.False(t, ok)
+}
+
+type conflictTestService struct {
+	name string
+	path string
+}
+
+func (s *conflictTestService) Name() string { return s.name }
+
+func (s *conflictTestService) RegisterGroup(g *gin.RouterGroup) {
+	g.GET(s.path, func(c *gin.Context) { c.Status(http.StatusOK) })
+}
+
+type panicTestService struct{}
+
+func (s *panicTestService) RegisterGroup(g *gin.RouterGroup) {
+	panic("boom")
+}
+
+func TestRouteConflictSamePath(t *testing.T) {
+	engine := NewEngine(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort(), NewOptions())
+	engine.RegisterService(&conflictTestService{name: "users", path: "/users"})
+	engine.RegisterService(&conflictTestService{name: "accounts", path: "/users"})
+
+	err := engine.RunContext(context.Background())
+	require.ErrorIs(t, err, ErrRouteConflict)
+
+	var conflict *RouteConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, "accounts", conflict.Service)
+	assert.Equal(t, "/users", conflict.Path)
+	assert.Equal(t, "users", conflict.ExistingService)
+	assert.Equal(t, http.MethodGet, conflict.ExistingMethod)
+	assert.Equal(t, "/users", conflict.ExistingPath)
+	assert.ErrorContains(t, err, "service accounts registering /users conflicts with GET /users of service users")
+	assert.False(t, engine.IsRunning())
+}
+
+func TestRouteConflictWildcard(t *testing.T) {
+	engine := NewEngine(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort(), NewOptions())
+	engine.RegisterService(&conflictTestService{name: "users", path: "/users/:id"})
+	engine.RegisterService(&conflictTestService{name: "profiles", path: "/users/:name/profile"})
+
+	err := engine.RunContext(context.Background())
+	var conflict *RouteConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, "profiles", conflict.Service)
+	assert.Equal(t, "/users/:name/profile", conflict.Path)
+	assert.Equal(t, "users", conflict.ExistingService)
+	assert.Equal(t, "/users/:id", conflict.ExistingPath)
+	assert.False(t, engine.IsRunning())
+}
+
+func TestRouteRegistrationPanic(t *testing.T) {
+	engine := NewEngine(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort(), NewOptions())
+	engine.RegisterService(&panicTestService{})
+
+	err := engine.RunContext(context.Background())
+	assert.ErrorContains(t, err, "service *orbit.panicTestService panicked while registering routes: boom")
+	assert.NotErrorIs(t, err, ErrRouteConflict)
+	assert.False(t, engine.IsRunning())
+}
+
+func TestRouteConflictStopsStartedServices(t *testing.T) {
+	var events []string
+	engine := NewEngine(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort(), NewOptions())
+	engine.RegisterService(&hookService{name: "hooks", events: &events})
+	engine.RegisterService(&conflictTestService{name: "a", path: "/dup"})
+	engine.RegisterService(&conflictTestService{name: "b", path: "/dup"})
+
+	err := engine.RunContext(context.Background())
+	assert.ErrorIs(t, err, ErrRouteConflict)
+	assert.ErrorIs(t, engine.GetRunError(), ErrRouteConflict)
+	assert.Nil(t, engine.listener)
+	assert.Equal(t, []string{"start:hooks", "stop:hooks"}, events)
+}
@@ -59,6 +59,8 @@ func metricService(group *gin.RouterGroup, registry *prometheus.Registry, logger
 	opts := promhttp.HandlerOpts{
 		ErrorLog:      metric.NewErrorLog(logger),
 		ErrorHandling: promhttp.ContinueOnError, // 继续处理后续请求
+		// 客户端协商 OpenMetrics 格式时输出 exemplar（链路 ID）
+		EnableOpenMetrics: true,
 	}
 
 	// 创建指标处理器
@@ -85,9 +87,14 @@ func swaggerService(group *gin.RouterGroup) {
 }
 
 // healthcheckService 将健康检查处理器注册到给定的路由组
-func healthcheckService(group *gin.RouterGroup) {
+// draining 返回 true 时，健康检查返回 503，便于负载均衡在关闭前摘除流量
+func healthcheckService(group *gin.RouterGroup, draining func() bool) {
 	// 使用预定义的状态码和响应，避免每次请求时创建新的字符串
 	group.GET(com.EmptyURLPath, func(c *gin.Context) {
+		if draining != nil && draining() {
+			c.Data(http.StatusServiceUnavailable, "text/plain; charset=utf-8", conver.StringToBytes(com.RequestDraining))
+			return
+		}
 		c.Data(http.StatusOK, "text/plain; charset=utf-8", conver.StringToBytes(com.RequestOK))
 	})
 }
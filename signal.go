@@ -0,0 +1,78 @@
+package orbit
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// 注册 SIGHUP 触发的重载函数，多个重载函数按注册顺序执行
+func (e *Engine) RegisterReloadFunc(fn func(ctx context.Context) error) {
+	if fn == nil {
+		return
+	}
+	e.reloadMu.Lock()
+	e.reloadFuncs = append(e.reloadFuncs, fn)
+	e.reloadMu.Unlock()
+}
+
+// 启动信号处理协程，每次运行独立监听，引擎停止后退出
+// SIGINT/SIGTERM 触发优雅关闭，关闭过程中再次收到终止信号时强制退出进程，SIGHUP 触发重载
+func (e *Engine) startSignalHandler() {
+	if !e.opts.signal {
+		return
+	}
+
+	// 优先使用注入的信号通道，便于测试
+	signals := e.config.signalCh
+	var notifyCh chan os.Signal
+	if signals == nil {
+		notifyCh = make(chan os.Signal, 2)
+		signal.Notify(notifyCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+		signals = notifyCh
+	}
+
+	runCtx := e.ctx
+	go func() {
+		if notifyCh != nil {
+			defer signal.Stop(notifyCh)
+		}
+
+		stopping := false
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case sig := <-signals:
+				if sig == syscall.SIGHUP {
+					e.reload(runCtx)
+					continue
+				}
+				if stopping {
+					e.config.logger.Info("received second termination signal, force exit", "signal", sig.String())
+					e.exitFunc(1)
+					return
+				}
+				stopping = true
+				e.config.logger.Info("received termination signal, shutting down gracefully", "signal", sig.String())
+				go e.Stop()
+			}
+		}
+	}()
+}
+
+// 按注册顺序执行重载函数，记录执行失败的错误
+func (e *Engine) reload(ctx context.Context) {
+	e.reloadMu.Lock()
+	funcs := make([]func(context.Context) error, len(e.reloadFuncs))
+	copy(funcs, e.reloadFuncs)
+	e.reloadMu.Unlock()
+
+	e.config.logger.Info("received reload signal", "handlers", len(funcs))
+	for _, fn := range funcs {
+		if err := fn(ctx); err != nil {
+			e.config.logger.Error(err, "failed to reload")
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package orbit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSignalEngine(t *testing.T, config *Config) (*Engine, chan os.Signal) {
+	t.Helper()
+
+	signals := make(chan os.Signal, 1)
+	engine := NewEngine(config.WithRelease().WithEphemeralPort().WithSignalChannel(signals), NewOptions().EnableSignalHandling())
+	return engine, signals
+}
+
+func TestSignalTerminateStopsEngine(t *testing.T) {
+	engine, signals := newSignalEngine(t, NewConfig())
+
+	done := make(chan error, 1)
+	go func() { done <- engine.RunContext(context.Background()) }()
+	<-engine.Ready()
+
+	signals <- syscall.SIGTERM
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("engine not stopped after SIGTERM")
+	}
+	assert.Equal(t, EngineStateStopped, engine.State())
+}
+
+func TestSignalReloadCallsHandlers(t *testing.T) {
+	engine, signals := newSignalEngine(t, NewConfig())
+
+	var order []string
+	reloaded := make(chan struct{}, 1)
+	engine.RegisterReloadFunc(func(context.Context) error {
+		order = append(order, "first")
+		return errors.New("bad config")
+	})
+	engine.RegisterReloadFunc(func(context.Context) error {
+		order = append(order, "second")
+		reloaded <- struct{}{}
+		return nil
+	})
+	engine.RegisterReloadFunc(nil)
+
+	engine.Run()
+	defer engine.Stop()
+	require.True(t, engine.IsRunning())
+
+	signals <- syscall.SIGHUP
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reload handlers not called after SIGHUP")
+	}
+
+	// 重载失败不影响后续重载函数，也不会停止引擎
+	assert.Equal(t, []string{"first", "second"}, order)
+	assert.True(t, engine.IsRunning())
+}
+
+func TestSignalSecondTerminateForcesExit(t *testing.T) {
+	engine, signals := newSignalEngine(t, NewConfig().WithPreStopDelay(500))
+
+	var exitCode atomic.Int32
+	exitCode.Store(-1)
+	engine.exitFunc = func(code int) { exitCode.Store(int32(code)) }
+
+	engine.Run()
+	require.True(t, engine.IsRunning())
+
+	signals <- syscall.SIGTERM
+	require.Eventually(t, engine.IsDraining, time.Second, 5*time.Millisecond)
+
+	signals <- os.Interrupt
+	require.Eventually(t, func() bool { return exitCode.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	require.Eventually(t, func() bool { return engine.State() == EngineStateStopped }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSignalHandlingDisabledByDefault(t *testing.T) {
+	signals := make(chan os.Signal, 1)
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort().WithSignalChannel(signals), NewOptions())
+	engine.Run()
+	defer engine.Stop()
+
+	signals <- syscall.SIGTERM
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, engine.IsRunning())
+	assert.Len(t, signals, 1)
+}
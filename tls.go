@@ -0,0 +1,52 @@
+package orbit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shengyanli1982/orbit/internal/tlsutil"
+)
+
+// 初始化 TLS 配置，加载证书并构建服务端 tls.Config
+func (e *Engine) initTLS() error {
+	// 只配置了证书或私钥其中之一时拒绝启动，避免静默降级为 HTTP
+	if t := e.config.TLS; t != nil && (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("invalid tls config: certFile and keyFile must be set together")
+	}
+	if !e.config.TLS.IsEnabled() {
+		return nil
+	}
+
+	reloader, err := tlsutil.NewCertReloader(e.config.TLS.CertFile, e.config.TLS.KeyFile, e.config.logger)
+	if err != nil {
+		return err
+	}
+
+	tlsConfig, err := tlsutil.NewServerConfig(e.config.TLS, reloader)
+	if err != nil {
+		return fmt.Errorf("invalid tls config: %w", err)
+	}
+
+	e.certReloader = reloader
+	e.tlsConfig = tlsConfig
+	return nil
+}
+
+// 启动证书文件监听协程，证书变化时自动重新加载
+func (e *Engine) startCertWatcher() {
+	if e.certReloader == nil {
+		return
+	}
+
+	interval := time.Duration(e.config.TLS.ReloadIntervalMillis) * time.Millisecond
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.certReloader.Watch(e.ctx, interval)
+	}()
+}
+
+// 返回服务器是否以 HTTPS 方式提供服务
+func (e *Engine) IsTLSEnabled() bool {
+	return e.tlsConfig != nil
+}
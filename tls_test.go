@@ -0,0 +1,278 @@
+package orbit
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/shengyanli1982/orbit/utils/httptool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 生成自签名证书并写入指定目录
+func writeTestKeyPair(t *testing.T, dir, commonName string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return certFile, keyFile
+}
+
+// 生成 CA 证书并写入指定目录
+func writeTestCA(t *testing.T, dir string) (string, *x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "orbit-test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	return caFile, ca, key
+}
+
+// 使用 CA 签发带 SPIFFE ID 的客户端证书
+func newTestClientCertificate(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, spiffeID string) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	uri, err := url.Parse(spiffeID)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "orbit-client"},
+		URIs:         []*url.URL{uri},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestEngineServeTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, dir, "orbit-tls")
+
+	config := NewConfig().WithPort(18443).WithRelease().WithTLS(com.TLSConfig{
+		CertFile:             certFile,
+		KeyFile:              keyFile,
+		ReloadIntervalMillis: 10,
+	})
+	engine := NewEngine(config, NewOptions())
+	require.NoError(t, engine.initErr)
+	assert.True(t, engine.IsTLSEnabled())
+
+	engine.Run()
+	defer engine.Stop()
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		Timeout:   time.Second,
+	}
+
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		var err error
+		resp, err = client.Get("https://127.0.0.1:18443" + com.HealthCheckURLPath)
+		return err == nil
+	}, 2*time.Second, 20*time.Millisecond)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, com.RequestOK, string(body))
+	assert.Equal(t, "orbit-tls", resp.TLS.PeerCertificates[0].Subject.CommonName)
+
+	// 轮换证书后，新的握手应使用新证书
+	future := time.Now().Add(time.Minute)
+	writeTestKeyPair(t, dir, "orbit-tls-rotated")
+	require.NoError(t, os.Chtimes(certFile, future, future))
+
+	assert.Eventually(t, func() bool {
+		conn, err := tls.Dial("tcp", "127.0.0.1:18443", &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return false
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName == "orbit-tls-rotated"
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestEngineTLSInitFailsWithMissingCertificate(t *testing.T) {
+	config := NewConfig().WithTLS(com.TLSConfig{CertFile: "/not/exist.crt", KeyFile: "/not/exist.key"})
+	engine := NewEngine(config, NewOptions())
+
+	assert.Error(t, engine.initErr)
+
+	engine.Run()
+	assert.False(t, engine.IsRunning())
+}
+
+func TestEngineTLSInitFailsWithPartialKeyPair(t *testing.T) {
+	certFile, keyFile := writeTestKeyPair(t, t.TempDir(), "orbit-tls")
+
+	for _, conf := range []com.TLSConfig{{CertFile: certFile}, {KeyFile: keyFile}} {
+		engine := NewEngine(NewConfig().WithAddress("127.0.0.1").WithEphemeralPort().WithTLS(conf), NewOptions())
+		assert.ErrorContains(t, engine.initErr, "certFile and keyFile must be set together")
+		assert.False(t, engine.IsTLSEnabled())
+
+		engine.Run()
+		assert.False(t, engine.IsRunning())
+	}
+}
+
+func TestEngineTLSInitFailsWithInvalidMinVersion(t *testing.T) {
+	certFile, keyFile := writeTestKeyPair(t, t.TempDir(), "orbit-tls")
+	config := NewConfig().WithTLS(com.TLSConfig{CertFile: certFile, KeyFile: keyFile, MinVersion: "0.9"})
+	engine := NewEngine(config, NewOptions())
+
+	assert.Error(t, engine.initErr)
+}
+
+type peerIdentityService struct{}
+
+func (s *peerIdentityService) RegisterGroup(g *gin.RouterGroup) {
+	g.GET("/whoami", func(c *gin.Context) {
+		identity, ok := httptool.GetPeerIdentityFromContext(c)
+		if !ok {
+			c.String(http.StatusOK, "anonymous")
+			return
+		}
+		c.String(http.StatusOK, identity.SPIFFEID)
+	})
+}
+
+func TestEngineMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, dir, "orbit-tls")
+	caFile, ca, caKey := writeTestCA(t, dir)
+
+	config := NewConfig().WithPort(18444).WithRelease().WithTLS(com.TLSConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientAuth:   com.TLSClientAuthVerifyIfGiven,
+		ClientCAFile: caFile,
+	})
+	engine := NewEngine(config, NewOptions())
+	require.NoError(t, engine.initErr)
+	engine.RegisterService(&peerIdentityService{})
+	engine.Run()
+	defer engine.Stop()
+
+	get := func(certs ...tls.Certificate) (string, error) {
+		client := &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true, Certificates: certs}},
+			Timeout:   time.Second,
+		}
+		resp, err := client.Get("https://127.0.0.1:18444/whoami")
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	// 未提供客户端证书时允许访问，但没有身份信息
+	require.Eventually(t, func() bool {
+		body, err := get()
+		return err == nil && body == "anonymous"
+	}, 2*time.Second, 20*time.Millisecond)
+
+	// 提供经过 CA 签发的证书时，处理函数可获取 SPIFFE ID
+	body, err := get(newTestClientCertificate(t, ca, caKey, "spiffe://example.org/sa/client"))
+	require.NoError(t, err)
+	assert.Equal(t, "spiffe://example.org/sa/client", body)
+
+	// 未经 CA 签发的证书会被拒绝
+	_, otherCA, otherKey := writeTestCA(t, t.TempDir())
+	_, err = get(newTestClientCertificate(t, otherCA, otherKey, "spiffe://example.org/sa/rogue"))
+	assert.Error(t, err)
+}
+
+func TestEngineMutualTLSRequireRejectsAnonymousClient(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, dir, "orbit-tls")
+	caFile, _, _ := writeTestCA(t, dir)
+
+	config := NewConfig().WithPort(18445).WithRelease().WithTLS(com.TLSConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientAuth:   com.TLSClientAuthRequire,
+		ClientCAFile: caFile,
+	})
+	engine := NewEngine(config, NewOptions())
+	require.NoError(t, engine.initErr)
+	engine.Run()
+	defer engine.Stop()
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		Timeout:   time.Second,
+	}
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", "127.0.0.1:18445")
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 2*time.Second, 20*time.Millisecond)
+
+	_, err := client.Get("https://127.0.0.1:18445" + com.HealthCheckURLPath)
+	assert.Error(t, err)
+}
@@ -0,0 +1,44 @@
+package orbit
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// 等待新进程就绪的默认超时时间
+const defaultUpgradeReadyTimeout = 30 * time.Second
+
+// 升级相关的错误
+var (
+	ErrUpgradeNotSupported = errors.New("upgrade is not supported on this platform")
+	ErrUpgradeNotRunning   = errors.New("upgrade requires a running engine")
+)
+
+// UpgradeOptions 定义进程升级时新进程的启动参数
+type UpgradeOptions struct {
+	Path         string        // 新进程的可执行文件路径，为空时使用当前可执行文件
+	Args         []string      // 新进程的启动参数（不包含程序名），nil 时沿用当前进程的参数
+	Env          []string      // 追加给新进程的环境变量
+	ReadyTimeout time.Duration // 等待新进程就绪的超时时间，0 表示使用默认值
+	Stdout       io.Writer     // 新进程的标准输出，nil 时使用当前进程的标准输出
+	Stderr       io.Writer     // 新进程的标准错误，nil 时使用当前进程的标准错误
+}
+
+// 验证升级参数的有效性，并设置默认值
+func isUpgradeOptionsValid(opts *UpgradeOptions) *UpgradeOptions {
+	if opts == nil {
+		opts = &UpgradeOptions{}
+	}
+	if opts.ReadyTimeout <= 0 {
+		opts.ReadyTimeout = defaultUpgradeReadyTimeout
+	}
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = os.Stderr
+	}
+	return opts
+}
@@ -0,0 +1,90 @@
+//go:build !windows
+
+package orbit
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 标记当前进程是升级测试启动的子进程
+const upgradeChildEnv = "ORBIT_TEST_UPGRADE_CHILD"
+
+// 升级测试中被父进程启动的子进程
+func TestUpgradeHelperProcess(t *testing.T) {
+	if os.Getenv(upgradeChildEnv) != "1" {
+		t.Skip("helper process for TestEngineUpgrade")
+	}
+
+	svc := &whoService{name: "child", quit: make(chan struct{})}
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), EmptyOptions())
+	engine.RegisterService(svc)
+	engine.Run()
+	if !engine.IsRunning() {
+		os.Exit(2)
+	}
+
+	select {
+	case <-svc.quit:
+	case <-time.After(30 * time.Second):
+	}
+	engine.Stop()
+}
+
+func TestEngineUpgrade(t *testing.T) {
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), EmptyOptions())
+	engine.RegisterService(&whoService{name: "parent", quit: make(chan struct{})})
+	engine.Run()
+	require.True(t, engine.IsRunning())
+
+	endpoint := engine.GetListenEndpoint()
+	assert.Equal(t, "parent", getWho(t, endpoint))
+
+	err := engine.Upgrade(&UpgradeOptions{
+		Args:         []string{"-test.run=^TestUpgradeHelperProcess$"},
+		Env:          []string{upgradeChildEnv + "=1"},
+		ReadyTimeout: 10 * time.Second,
+		Stdout:       io.Discard,
+		Stderr:       io.Discard,
+	})
+	require.NoError(t, err)
+
+	// 当前引擎已停止，同一地址由子进程继续提供服务
+	assert.False(t, engine.IsRunning())
+	assert.Equal(t, "child", getWho(t, endpoint))
+
+	resp, err := http.Post("http://"+endpoint+"/quit", "text/plain", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestEngineUpgradeFailsWhenChildNotReady(t *testing.T) {
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), EmptyOptions())
+	engine.RegisterService(&whoService{name: "parent", quit: make(chan struct{})})
+	engine.Run()
+	defer engine.Stop()
+
+	// 子进程没有匹配的测试，启动后直接退出
+	err := engine.Upgrade(&UpgradeOptions{
+		Args:         []string{"-test.run=^$"},
+		ReadyTimeout: 10 * time.Second,
+		Stdout:       io.Discard,
+		Stderr:       io.Discard,
+	})
+	assert.Error(t, err)
+
+	// 升级失败时当前引擎继续提供服务
+	assert.True(t, engine.IsRunning())
+	assert.Equal(t, "parent", getWho(t, engine.GetListenEndpoint()))
+}
+
+func TestEngineUpgradeRequiresRunningEngine(t *testing.T) {
+	engine := NewEngine(NewConfig().WithRelease().WithEphemeralPort(), EmptyOptions())
+	assert.ErrorIs(t, engine.Upgrade(nil), ErrUpgradeNotRunning)
+}
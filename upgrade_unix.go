@@ -0,0 +1,208 @@
+//go:build !windows
+
+package orbit
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shengyanli1982/orbit/internal/listener"
+)
+
+// 可被继承的监听器，TCP 与 Unix 域套接字监听器都实现了该接口
+type filer interface {
+	File() (*os.File, error)
+}
+
+// Upgrade 启动新版本的进程并将监听套接字交给它，实现不中断服务的原地升级
+// 新进程通过 LISTEN_FDS/LISTEN_FDNAMES 继承监听套接字，并在引擎启动后通知就绪；
+// 收到就绪通知后，当前引擎会排空请求并停止，调用方随后退出进程即可
+func (e *Engine) Upgrade(opts *UpgradeOptions) error {
+	if !e.IsRunning() {
+		return ErrUpgradeNotRunning
+	}
+	opts = isUpgradeOptionsValid(opts)
+
+	// 收集需要交给新进程的监听套接字
+	files, names, err := e.inheritableFiles()
+	if err != nil {
+		return err
+	}
+	defer closeFiles(files)
+
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to create upgrade ready pipe: %w", err)
+	}
+	defer readyReader.Close()
+
+	cmd, err := e.upgradeCommand(opts, files, names, readyWriter)
+	if err != nil {
+		_ = readyWriter.Close()
+		return err
+	}
+
+	err = cmd.Start()
+	_ = readyWriter.Close()
+	// 启动子进程时会把共享的套接字切换为阻塞模式，这里恢复为非阻塞模式
+	e.restoreNonblock()
+	if err != nil {
+		return fmt.Errorf("failed to start upgrade process: %w", err)
+	}
+	e.config.logger.Info("upgrade process started, waiting for ready", "pid", cmd.Process.Pid)
+
+	if err := waitUpgradeReady(readyReader, opts.ReadyTimeout); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("upgrade process %d failed to become ready: %w", cmd.Process.Pid, err)
+	}
+	e.config.logger.Info("upgrade process is ready, draining current engine", "pid", cmd.Process.Pid)
+	_ = cmd.Process.Release()
+
+	// 套接字文件已由新进程接管，关闭时不能删除
+	if ul, ok := e.listener.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+
+	e.Stop()
+	return nil
+}
+
+// 收集业务端与管理端监听器的文件描述符副本
+func (e *Engine) inheritableFiles() ([]*os.File, []string, error) {
+	files := make([]*os.File, 0, 2)
+	names := make([]string, 0, 2)
+
+	listeners := []struct {
+		name     string
+		listener net.Listener
+	}{
+		{httpServerName, e.listener},
+		{adminServerName, e.adminListener},
+	}
+	for _, item := range listeners {
+		if item.listener == nil {
+			continue
+		}
+		fl, ok := item.listener.(filer)
+		if !ok {
+			closeFiles(files)
+			return nil, nil, fmt.Errorf("%s listener %T can not be inherited", item.name, item.listener)
+		}
+		f, err := fl.File()
+		if err != nil {
+			closeFiles(files)
+			return nil, nil, fmt.Errorf("failed to get %s listener file: %w", item.name, err)
+		}
+		files = append(files, f)
+		names = append(names, item.name)
+	}
+
+	return files, names, nil
+}
+
+// 构建新进程的启动命令
+func (e *Engine) upgradeCommand(opts *UpgradeOptions, files []*os.File, names []string, ready *os.File) (*exec.Cmd, error) {
+	path := opts.Path
+	if path == "" {
+		executable, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate current executable: %w", err)
+		}
+		path = executable
+	}
+
+	args := opts.Args
+	if args == nil && len(os.Args) > 1 {
+		args = os.Args[1:]
+	}
+
+	// 过滤掉当前进程中残留的继承相关环境变量
+	env := make([]string, 0, len(os.Environ())+len(opts.Env)+3)
+	for _, kv := range os.Environ() {
+		if isInheritEnv(kv) {
+			continue
+		}
+		env = append(env, kv)
+	}
+	env = append(env,
+		listener.EnvListenFds+"="+strconv.Itoa(len(files)),
+		listener.EnvListenFdNames+"="+strings.Join(names, ":"),
+		listener.EnvUpgradeReadyFd+"="+strconv.Itoa(listener.ListenFdsStart+len(files)),
+	)
+	env = append(env, opts.Env...)
+
+	cmd := exec.Command(path, args...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	cmd.ExtraFiles = append(append(make([]*os.File, 0, len(files)+1), files...), ready)
+	return cmd, nil
+}
+
+// 恢复监听套接字的非阻塞模式
+func (e *Engine) restoreNonblock() {
+	for _, l := range []net.Listener{e.listener, e.adminListener} {
+		sc, ok := l.(syscall.Conn)
+		if !ok {
+			continue
+		}
+		if raw, err := sc.SyscallConn(); err == nil {
+			_ = raw.Control(func(fd uintptr) {
+				_ = syscall.SetNonblock(int(fd), true)
+			})
+		}
+	}
+}
+
+// 等待新进程写入就绪通知，新进程退出或超时都视为失败
+func waitUpgradeReady(r *os.File, timeout time.Duration) error {
+	result := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 8)
+		n, err := r.Read(buf)
+		if n > 0 {
+			result <- nil
+			return
+		}
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		result <- err
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("timeout after %s", timeout)
+	}
+}
+
+// 判断环境变量是否与监听套接字继承相关
+func isInheritEnv(kv string) bool {
+	for _, key := range []string{listener.EnvListenFds, listener.EnvListenPid, listener.EnvListenFdNames, listener.EnvUpgradeReadyFd} {
+		if strings.HasPrefix(kv, key+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// 关闭文件列表
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		_ = f.Close()
+	}
+}
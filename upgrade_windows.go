@@ -0,0 +1,8 @@
+//go:build windows
+
+package orbit
+
+// Upgrade 在 Windows 上不支持监听套接字的继承
+func (e *Engine) Upgrade(opts *UpgradeOptions) error {
+	return ErrUpgradeNotSupported
+}
@@ -21,3 +21,36 @@ func GetLoggerFromContext(context *gin.Context) *logr.Logger {
 
 	return &com.DefaultLogrLogger
 }
+
+// GetPeerIdentityFromContext 从 gin.Context 中获取经过校验的客户端证书身份
+// 仅在启用 mTLS 且客户端证书校验通过时存在
+func GetPeerIdentityFromContext(context *gin.Context) (*com.PeerIdentity, bool) {
+	if context == nil {
+		return nil, false
+	}
+
+	if obj, ok := context.Get(com.PeerIdentityKey); ok {
+		if identity, ok := obj.(*com.PeerIdentity); ok && identity != nil {
+			return identity, true
+		}
+	}
+
+	return nil, false
+}
+
+// GetRequestIDFromContext 从 gin.Context 中获取请求 ID
+// 启用请求 ID 中间件时返回其分配的请求 ID，否则返回请求头 X-Request-Id 的值
+func GetRequestIDFromContext(context *gin.Context) string {
+	if context == nil {
+		return ""
+	}
+
+	if id := context.GetString(com.RequestIDKey); id != "" {
+		return id
+	}
+
+	if context.Request == nil {
+		return ""
+	}
+	return context.GetHeader(com.HttpHeaderRequestID)
+}
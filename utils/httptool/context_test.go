@@ -1,6 +1,8 @@
 package httptool
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -33,3 +35,42 @@ func TestGetLoggerFromContext(t *testing.T) {
 	result = GetLoggerFromContext(context)
 	assert.Equal(t, defaultLogger, result)
 }
+
+func TestGetPeerIdentityFromContext(t *testing.T) {
+	// Test with nil context
+	identity, ok := GetPeerIdentityFromContext(nil)
+	assert.False(t, ok)
+	assert.Nil(t, identity)
+
+	// Test when PeerIdentityKey does not exist in the context
+	context := &gin.Context{}
+	_, ok = GetPeerIdentityFromContext(context)
+	assert.False(t, ok)
+
+	// Test when PeerIdentityKey exists with *com.PeerIdentity
+	context = &gin.Context{}
+	context.Set(com.PeerIdentityKey, &com.PeerIdentity{Subject: "CN=client"})
+	identity, ok = GetPeerIdentityFromContext(context)
+	assert.True(t, ok)
+	assert.Equal(t, "CN=client", identity.Subject)
+
+	// Test when PeerIdentityKey exists with unsupported type
+	context = &gin.Context{}
+	context.Set(com.PeerIdentityKey, "unsupported")
+	_, ok = GetPeerIdentityFromContext(context)
+	assert.False(t, ok)
+}
+
+func TestGetRequestIDFromContext(t *testing.T) {
+	assert.Empty(t, GetRequestIDFromContext(nil))
+	assert.Empty(t, GetRequestIDFromContext(&gin.Context{}))
+
+	// 未启用请求 ID 中间件时使用请求头中的值
+	context := &gin.Context{Request: httptest.NewRequest(http.MethodGet, "/", nil)}
+	context.Request.Header.Set(com.HttpHeaderRequestID, "from-header")
+	assert.Equal(t, "from-header", GetRequestIDFromContext(context))
+
+	// 中间件分配的请求 ID 优先
+	context.Set(com.RequestIDKey, "assigned")
+	assert.Equal(t, "assigned", GetRequestIDFromContext(context))
+}
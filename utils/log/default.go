@@ -9,11 +9,14 @@ func DefaultAccessEventFunc(logger *logr.Logger, event *LogEvent) {
 	logger.Info(
 		event.Message,
 		"id", event.ID,
+		"traceId", event.TraceID,
+		"spanId", event.SpanID,
 		"ip", event.IP,
 		"forwardedFor", event.ForwardedFor,
 		"endpoint", event.EndPoint,
 		"path", event.Path,
 		"method", event.Method,
+		"service", event.Service,
 		"code", event.Code,
 		"status", event.Status,
 		"latency", event.Latency,
@@ -21,6 +24,9 @@ func DefaultAccessEventFunc(logger *logr.Logger, event *LogEvent) {
 		"query", event.ReqQuery,
 		"reqContentType", event.ReqContentType,
 		"reqBody", event.ReqBody,
+		"peerSubject", event.PeerSubject,
+		"peerSANs", event.PeerSANs,
+		"peerSpiffeId", event.PeerSPIFFEID,
 	)
 }
 
@@ -30,11 +36,14 @@ func DefaultRecoveryEventFunc(logger *logr.Logger, event *LogEvent) {
 		event.Error,
 		event.Message,
 		"id", event.ID,
+		"traceId", event.TraceID,
+		"spanId", event.SpanID,
 		"ip", event.IP,
 		"forwardedFor", event.ForwardedFor,
 		"endpoint", event.EndPoint,
 		"path", event.Path,
 		"method", event.Method,
+		"service", event.Service,
 		"code", event.Code,
 		"status", event.Status,
 		"latency", event.Latency,
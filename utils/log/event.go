@@ -8,6 +8,12 @@ type LogEvent struct {
 	// 事件的唯一标识符
 	ID string `json:"id,omitempty" yaml:"id,omitempty"`
 
+	// 请求所属链路的 ID（W3C Trace Context）
+	TraceID string `json:"traceId,omitempty" yaml:"traceId,omitempty"`
+
+	// 处理请求的服务端 Span ID
+	SpanID string `json:"spanId,omitempty" yaml:"spanId,omitempty"`
+
 	// 发起请求的IP地址
 	IP string `json:"ip,omitempty" yaml:"ip,omitempty"`
 
@@ -20,6 +26,9 @@ type LogEvent struct {
 	// 请求的HTTP方法
 	Method string `json:"method,omitempty" yaml:"method,omitempty"`
 
+	// 处理请求的服务名称
+	Service string `json:"service,omitempty" yaml:"service,omitempty"`
+
 	// 响应的HTTP状态码
 	Code int `json:"statusCode,omitempty" yaml:"statusCode,omitempty"`
 
@@ -44,6 +53,15 @@ type LogEvent struct {
 	// 请求的主体内容
 	ReqBody string `json:"reqBody,omitempty" yaml:"reqBody,omitempty"`
 
+	// 客户端证书主题（mTLS）
+	PeerSubject string `json:"peerSubject,omitempty" yaml:"peerSubject,omitempty"`
+
+	// 客户端证书的主题备用名称（mTLS）
+	PeerSANs []string `json:"peerSANs,omitempty" yaml:"peerSANs,omitempty"`
+
+	// 客户端证书中的 SPIFFE ID（mTLS）
+	PeerSPIFFEID string `json:"peerSpiffeId,omitempty" yaml:"peerSpiffeId,omitempty"`
+
 	// 请求中的任何错误
 	Error error `json:"error,omitempty" yaml:"error,omitempty"`
 
@@ -55,10 +73,13 @@ type LogEvent struct {
 func (e *LogEvent) Reset() {
 	e.Message = ""
 	e.ID = ""
+	e.TraceID = ""
+	e.SpanID = ""
 	e.IP = ""
 	e.EndPoint = ""
 	e.Path = ""
 	e.Method = ""
+	e.Service = ""
 	e.Code = 0
 	e.Status = ""
 	e.Latency = ""
@@ -67,6 +88,9 @@ func (e *LogEvent) Reset() {
 	e.ReqContentType = ""
 	e.ReqQuery = ""
 	e.ReqBody = ""
+	e.PeerSubject = ""
+	e.PeerSANs = nil
+	e.PeerSPIFFEID = ""
 	e.Error = nil
 	e.ErrorStack = ""
 }
@@ -47,6 +47,7 @@ type ZapLogger struct {
 	rl  *logr.Logger       // Logr 接口日志记录器
 	sl  *log.Logger        // 标准库日志记录器
 	sul *zap.SugaredLogger // Zap 语法糖日志记录器
+	lvl zap.AtomicLevel    // 可在运行时调整的日志级别
 }
 
 // 创建并返回一个新的 ZapLogger 实例
@@ -63,16 +64,17 @@ func NewZapLogger(ws zapcore.WriteSyncer, isRelease bool, opts ...zap.Option) *Z
 	}
 
 	// 创建核心日志组件
+	lvl := zap.NewAtomicLevelAt(logLevel)
 	core := zapcore.NewCore(
 		zapcore.NewJSONEncoder(LogEncodingConfig),
 		ws,
-		zap.NewAtomicLevelAt(logLevel),
+		lvl,
 	)
 
 	// 初始化各种日志记录器
 	l := zap.New(core, zap.AddCaller()).WithOptions(opts...)
 	rl := zapr.NewLogger(l)
-	return &ZapLogger{l: l, rl: &rl, sul: l.Sugar(), sl: zap.NewStdLog(l)}
+	return &ZapLogger{l: l, rl: &rl, sul: l.Sugar(), sl: zap.NewStdLog(l), lvl: lvl}
 }
 
 // 根据运行模式创建合适的 ZapLogger 实例的便利函数
@@ -101,3 +103,13 @@ func (l *ZapLogger) GetStandardLogger() *log.Logger {
 func (l *ZapLogger) GetLogrLogger() *logr.Logger {
 	return l.rl
 }
+
+// 返回当前的日志级别
+func (l *ZapLogger) GetLevel() zapcore.Level {
+	return l.lvl.Level()
+}
+
+// 设置日志级别，可在运行时并发调用
+func (l *ZapLogger) SetLevel(level zapcore.Level) {
+	l.lvl.SetLevel(level)
+}
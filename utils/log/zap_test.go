@@ -57,3 +57,23 @@ func TestGetZapStdLogger(t *testing.T) {
 	stdLogger.Print("test message")
 	assert.Contains(t, buff.String(), "test message", "buffer should contain the message")
 }
+
+func TestZapLoggerSetLevel(t *testing.T) {
+	// Create a new buffer
+	buff := bytes.NewBuffer(make([]byte, 0, 1024))
+
+	// Create a new logger at Debug level
+	logger := NewZapLogger(zapcore.AddSync(buff), false)
+	assert.Equal(t, zapcore.DebugLevel, logger.GetLevel())
+
+	// Raise the level, Info messages should be dropped
+	logger.SetLevel(zapcore.WarnLevel)
+	assert.Equal(t, zapcore.WarnLevel, logger.GetLevel())
+	logger.GetLogrLogger().Info("dropped message")
+	assert.NotContains(t, buff.String(), "dropped message")
+
+	// Lower the level again, Info messages should be logged
+	logger.SetLevel(zapcore.InfoLevel)
+	logger.GetLogrLogger().Info("kept message")
+	assert.Contains(t, buff.String(), "kept message")
+}
@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+)
+
+// 默认的 Retry-After 时间
+const defaultShedRetryAfter = time.Second
+
+// 自适应模式默认的乘性减少系数
+const defaultAdaptiveBackoff = 0.9
+
+// 自适应模式默认的目标请求耗时
+const defaultAdaptiveTargetLatency = 100 * time.Millisecond
+
+// 自适应模式默认的并发上限最大值
+const defaultAdaptiveLimit = 1000
+
+// 请求被卸载时返回的响应体
+var shedResponseBody, _ = json.Marshal(com.ErrorResponse{Code: com.RequestErrorCode, Message: com.RequestOverloaded})
+
+// AdaptiveConfig 定义自适应并发限制（AIMD）的配置
+// 请求耗时不超过目标时并发上限缓慢增加，超过目标时按系数减少，减少操作每个目标耗时周期最多执行一次
+type AdaptiveConfig struct {
+	TargetLatency time.Duration // 目标请求耗时，不大于 0 时为 100 毫秒
+	MinLimit      int           // 并发上限的最小值，不大于 0 时为 1
+	Backoff       float64       // 超过目标耗时时的乘性减少系数，取值 (0, 1)，默认 0.9
+}
+
+// ConcurrencyLimitConfig 定义并发限制中间件的配置
+type ConcurrencyLimitConfig struct {
+	Limit        int             // 最大并发请求数，不大于 0 时不限制；自适应模式下为并发上限的最大值，不大于 0 时为 1000
+	QueueSize    int             // 等待队列长度，0 表示不排队，超过并发数的请求直接被拒绝
+	QueueTimeout time.Duration   // 请求在队列中的最长等待时间，0 表示等待到请求的 context 结束
+	RetryAfter   time.Duration   // 被拒绝时 Retry-After 头的时间，默认 1 秒
+	Adaptive     *AdaptiveConfig // 自适应模式的配置，为 nil 时使用固定的并发上限
+}
+
+// ConcurrencyLimiter 限制同时处理的请求数量，超出并发上限的请求进入有界队列等待，队列已满或等待超时的请求被拒绝
+type ConcurrencyLimiter struct {
+	config ConcurrencyLimitConfig
+
+	mu           sync.Mutex
+	limit        float64    // 当前并发上限
+	inFlight     int        // 正在处理的请求数
+	waiters      *list.List // 等待中的请求，元素为 chan struct{}
+	lastDecrease time.Time  // 自适应模式上次减少并发上限的时间
+	now          func() time.Time
+}
+
+// NewConcurrencyLimiter 创建并发限制器
+func NewConcurrencyLimiter(config ConcurrencyLimitConfig) *ConcurrencyLimiter {
+	if config.QueueSize < 0 {
+		config.QueueSize = 0
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = defaultShedRetryAfter
+	}
+	if adaptive := config.Adaptive; adaptive != nil {
+		if config.Limit <= 0 {
+			config.Limit = defaultAdaptiveLimit
+		}
+		copied := *adaptive
+		if copied.TargetLatency <= 0 {
+			copied.TargetLatency = defaultAdaptiveTargetLatency
+		}
+		if copied.MinLimit <= 0 {
+			copied.MinLimit = 1
+		}
+		if copied.MinLimit > config.Limit {
+			copied.MinLimit = config.Limit
+		}
+		if copied.Backoff <= 0 || copied.Backoff >= 1 {
+			copied.Backoff = defaultAdaptiveBackoff
+		}
+		config.Adaptive = &copied
+	}
+	return &ConcurrencyLimiter{config: config, limit: float64(config.Limit), waiters: list.New(), now: time.Now}
+}
+
+// ConcurrencyLimit 返回一个限制并发请求数量的 Gin 中间件，可以用于引擎、路由组或单个路由
+// 每次调用创建独立的限制器，需要读取限制器状态时使用 NewConcurrencyLimiter
+func ConcurrencyLimit(config ConcurrencyLimitConfig) gin.HandlerFunc {
+	return NewConcurrencyLimiter(config).HandlerFunc()
+}
+
+// Limit 返回当前的并发上限
+func (l *ConcurrencyLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// InFlight 返回正在处理的请求数
+func (l *ConcurrencyLimiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// HandlerFunc 返回限制器的 Gin 中间件
+// 健康检查、指标等资源路径不受限制；被拒绝的请求返回 503 JSON 错误和 Retry-After 头
+func (l *ConcurrencyLimiter) HandlerFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l.config.Limit <= 0 || SkipResources(c) {
+			c.Next()
+			return
+		}
+
+		if !l.acquire(c.Request.Context()) {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(l.config.RetryAfter)))
+			c.Set(com.RequestShedKey, true)
+			c.Data(http.StatusServiceUnavailable, com.HttpHeaderJSONContentTypeValue, shedResponseBody)
+			c.Abort()
+			return
+		}
+
+		start := l.now()
+		defer func() { l.release(l.now().Sub(start)) }()
+		c.Next()
+	}
+}
+
+// 获取一个并发名额，需要排队时等待到获得名额、队列等待超时或 ctx 结束
+func (l *ConcurrencyLimiter) acquire(ctx context.Context) bool {
+	l.mu.Lock()
+	if l.inFlight < int(l.limit) && l.waiters.Len() == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		return true
+	}
+	if l.waiters.Len() >= l.config.QueueSize {
+		l.mu.Unlock()
+		return false
+	}
+	ready := make(chan struct{})
+	element := l.waiters.PushBack(ready)
+	l.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if l.config.QueueTimeout > 0 {
+		timer := time.NewTimer(l.config.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-ready:
+		return true
+	case <-timeout:
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-ready:
+		// 放弃等待的同时已经获得名额，归还后唤醒下一个等待者
+		l.inFlight--
+		l.wakeWaiters()
+	default:
+		l.waiters.Remove(element)
+	}
+	return false
+}
+
+// 归还一个并发名额，并在自适应模式下根据请求耗时调整并发上限
+func (l *ConcurrencyLimiter) release(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	if adaptive := l.config.Adaptive; adaptive != nil {
+		if latency > adaptive.TargetLatency {
+			now := l.now()
+			if now.Sub(l.lastDecrease) >= adaptive.TargetLatency {
+				l.lastDecrease = now
+				l.limit = math.Max(float64(adaptive.MinLimit), l.limit*adaptive.Backoff)
+			}
+		} else {
+			l.limit = math.Min(float64(l.config.Limit), l.limit+1/l.limit)
+		}
+	}
+	l.wakeWaiters()
+}
+
+// 按顺序唤醒等待者直到并发名额用尽，调用方需要持有锁
+func (l *ConcurrencyLimiter) wakeWaiters() {
+	for l.inFlight < int(l.limit) && l.waiters.Len() > 0 {
+		ready := l.waiters.Remove(l.waiters.Front()).(chan struct{})
+		l.inFlight++
+		close(ready)
+	}
+}
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 创建一个处理函数阻塞到 release 关闭的路由
+func newBlockingRouter(limiter *ConcurrencyLimiter, entered chan<- struct{}, release <-chan struct{}) *gin.Engine {
+	router := gin.New()
+	router.Use(limiter.HandlerFunc())
+	router.GET("/block", func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	router.GET(com.HealthCheckURLPath, func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+// 在后台发起请求并返回响应状态码的通道
+func serveAsync(router *gin.Engine, path string) <-chan int {
+	done := make(chan int, 1)
+	go func() {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		done <- recorder.Code
+	}()
+	return done
+}
+
+// 等待排队的请求数量达到 n
+func waitForWaiters(t *testing.T, limiter *ConcurrencyLimiter, n int) {
+	require.Eventually(t, func() bool {
+		limiter.mu.Lock()
+		defer limiter.mu.Unlock()
+		return limiter.waiters.Len() == n
+	}, time.Second, time.Millisecond)
+}
+
+func TestConcurrencyLimitRejectsOverLimit(t *testing.T) {
+	entered, release := make(chan struct{}, 1), make(chan struct{})
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{Limit: 1, RetryAfter: 2 * time.Second})
+	router := newBlockingRouter(limiter, entered, release)
+
+	first := serveAsync(router, "/block")
+	<-entered
+	assert.Equal(t, 1, limiter.InFlight())
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/block", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+	var response com.ErrorResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, com.RequestOverloaded, response.Message)
+
+	// 健康检查路径不受限制
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, com.HealthCheckURLPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-first)
+	assert.Equal(t, 0, limiter.InFlight())
+}
+
+func TestConcurrencyLimitQueuesRequests(t *testing.T) {
+	entered, release := make(chan struct{}, 2), make(chan struct{})
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{Limit: 1, QueueSize: 1})
+	router := newBlockingRouter(limiter, entered, release)
+
+	first := serveAsync(router, "/block")
+	<-entered
+	second := serveAsync(router, "/block")
+	waitForWaiters(t, limiter, 1)
+
+	// 队列已满时直接拒绝
+	third := serveAsync(router, "/block")
+	assert.Equal(t, http.StatusServiceUnavailable, <-third)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-first)
+	assert.Equal(t, http.StatusOK, <-second)
+	assert.Equal(t, 0, limiter.InFlight())
+}
+
+func TestConcurrencyLimitQueueTimeout(t *testing.T) {
+	entered, release := make(chan struct{}, 1), make(chan struct{})
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{Limit: 1, QueueSize: 1, QueueTimeout: 20 * time.Millisecond})
+	router := newBlockingRouter(limiter, entered, release)
+
+	first := serveAsync(router, "/block")
+	<-entered
+	assert.Equal(t, http.StatusServiceUnavailable, <-serveAsync(router, "/block"))
+	waitForWaiters(t, limiter, 0)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-first)
+}
+
+func TestConcurrencyLimitQueueContextCancel(t *testing.T) {
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{Limit: 1, QueueSize: 1})
+	require.True(t, limiter.acquire(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan bool, 1)
+	go func() { done <- limiter.acquire(ctx) }()
+	waitForWaiters(t, limiter, 1)
+	cancel()
+	assert.False(t, <-done)
+
+	limiter.release(0)
+	assert.Equal(t, 0, limiter.InFlight())
+}
+
+func TestConcurrencyLimitAdaptive(t *testing.T) {
+	now := time.Unix(1000, 0)
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{
+		Limit:    10,
+		Adaptive: &AdaptiveConfig{TargetLatency: 100 * time.Millisecond, MinLimit: 8, Backoff: 0.5},
+	})
+	limiter.now = func() time.Time { return now }
+
+	// 超过目标耗时时减少并发上限，同一周期内只减少一次
+	require.True(t, limiter.acquire(context.Background()))
+	limiter.release(time.Second)
+	assert.Equal(t, 8, limiter.Limit())
+	require.True(t, limiter.acquire(context.Background()))
+	limiter.release(time.Second)
+	assert.Equal(t, 8, limiter.Limit())
+
+	// 不会低于最小值
+	now = now.Add(time.Second)
+	require.True(t, limiter.acquire(context.Background()))
+	limiter.release(time.Second)
+	assert.Equal(t, 8, limiter.Limit())
+
+	// 耗时正常时缓慢恢复，不超过最大值
+	for i := 0; i < 100; i++ {
+		require.True(t, limiter.acquire(context.Background()))
+		limiter.release(time.Millisecond)
+	}
+	assert.Equal(t, 10, limiter.Limit())
+}
+
+func TestConcurrencyLimitAdaptiveDefaults(t *testing.T) {
+	// 自适应模式下未设置的并发上限和目标耗时使用默认值
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{Adaptive: &AdaptiveConfig{MinLimit: 2}})
+	assert.Equal(t, defaultAdaptiveLimit, limiter.Limit())
+	assert.Equal(t, defaultAdaptiveTargetLatency, limiter.config.Adaptive.TargetLatency)
+
+	// 耗时不超过默认目标时并发上限不会减少
+	require.True(t, limiter.acquire(context.Background()))
+	limiter.release(10 * time.Millisecond)
+	assert.Equal(t, defaultAdaptiveLimit, limiter.Limit())
+	require.True(t, limiter.acquire(context.Background()))
+	limiter.release(time.Second)
+	assert.Less(t, limiter.Limit(), defaultAdaptiveLimit)
+}
+
+func TestConcurrencyLimitDisabled(t *testing.T) {
+	router := gin.New()
+	router.Use(ConcurrencyLimit(ConcurrencyLimitConfig{}))
+	router.GET("/open", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/open", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -0,0 +1,215 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+)
+
+// 内存存储默认的分片数量
+const defaultRateLimitShards = 32
+
+// 内存存储清理空闲令牌桶的最小间隔
+const rateLimitSweepInterval = time.Minute
+
+// 请求被限流时返回的响应体
+var rateLimitResponseBody, _ = json.Marshal(com.ErrorResponse{Code: com.RequestErrorCode, Message: com.RequestRateLimited})
+
+// RateLimitKeyFunc 返回请求的限流键，返回空字符串时不对该请求限流
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// KeyByClientIP 按客户端 IP 限流，客户端 IP 由引擎按可信代理配置解析
+func KeyByClientIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// KeyByRoute 按路由模板限流，同一路由的所有请求共享一个令牌桶
+func KeyByRoute(c *gin.Context) string {
+	return c.Request.Method + " " + c.FullPath()
+}
+
+// KeyByHeader 按请求头的值限流，例如 API Key，请求头为空时不限流
+func KeyByHeader(name string) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		return c.GetHeader(name)
+	}
+}
+
+// RateLimitResult 描述一次取令牌的结果
+type RateLimitResult struct {
+	Allowed    bool          // 是否允许请求
+	Limit      int           // 令牌桶容量
+	Remaining  int           // 剩余令牌数
+	Reset      time.Duration // 令牌桶恢复满额所需的时间
+	RetryAfter time.Duration // 被拒绝时距离下一个可用令牌的时间
+}
+
+// RateLimitStore 定义令牌桶的存储，可以替换为 Redis 等共享存储
+type RateLimitStore interface {
+	// Take 从 key 对应的令牌桶中取出一个令牌，rate 为每秒补充的令牌数，burst 为令牌桶容量
+	Take(ctx context.Context, key string, rate float64, burst int) (RateLimitResult, error)
+}
+
+// RateLimitConfig 定义限流中间件的配置
+type RateLimitConfig struct {
+	Rate  float64          // 每秒补充的令牌数，不大于 0 时不限流
+	Burst int              // 令牌桶容量，不大于 0 时使用 Rate 向上取整（至少为 1）
+	Key   RateLimitKeyFunc // 限流键函数，为 nil 时按客户端 IP 限流
+	Store RateLimitStore   // 令牌桶存储，为 nil 时使用独立的内存存储
+}
+
+// RateLimit 返回一个基于令牌桶的限流 Gin 中间件，可以用于引擎、路由组或单个路由
+// 响应中带有 RateLimit-Limit、RateLimit-Remaining 和 RateLimit-Reset 头，被拒绝的请求返回 429 JSON 错误和 Retry-After 头
+// 存储返回错误时放行请求并将错误记录到 gin.Context 中
+// 多个限流中间件共享同一个存储时，需要保证它们的限流键互不冲突
+func RateLimit(config RateLimitConfig) gin.HandlerFunc {
+	if config.Rate <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if config.Burst <= 0 {
+		config.Burst = int(math.Max(1, math.Ceil(config.Rate)))
+	}
+	if config.Key == nil {
+		config.Key = KeyByClientIP
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryRateLimitStore(defaultRateLimitShards)
+	}
+
+	return func(c *gin.Context) {
+		key := config.Key(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		result, err := config.Store.Take(c.Request.Context(), key, config.Rate, config.Burst)
+		if err != nil {
+			_ = c.Error(err)
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			header.Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.Set(com.RequestRateLimitedKey, true)
+			c.Data(http.StatusTooManyRequests, com.HttpHeaderJSONContentTypeValue, rateLimitResponseBody)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// 将时间向上取整为秒
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
+
+// 令牌桶
+type tokenBucket struct {
+	tokens float64   // 当前令牌数
+	last   time.Time // 上次更新令牌数的时间
+}
+
+// 内存存储的分片
+type rateLimitShard struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// MemoryRateLimitStore 是按键分片的内存令牌桶存储，只在单个进程内生效
+// 恢复满额的空闲令牌桶会在访问时被定期清理
+type MemoryRateLimitStore struct {
+	shards []*rateLimitShard
+	now    func() time.Time
+}
+
+// NewMemoryRateLimitStore 创建内存令牌桶存储，shards 不大于 0 时使用默认分片数量
+func NewMemoryRateLimitStore(shards int) *MemoryRateLimitStore {
+	if shards <= 0 {
+		shards = defaultRateLimitShards
+	}
+	store := &MemoryRateLimitStore{shards: make([]*rateLimitShard, shards), now: time.Now}
+	for i := range store.shards {
+		store.shards[i] = &rateLimitShard{buckets: make(map[string]*tokenBucket)}
+	}
+	return store
+}
+
+// Take 从 key 对应的令牌桶中取出一个令牌
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, rate float64, burst int) (RateLimitResult, error) {
+	shard := s.shard(key)
+	now := s.now()
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	shard.sweep(now, rate, burst)
+
+	bucket, ok := shard.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(burst), last: now}
+		shard.buckets[key] = bucket
+	}
+
+	// 按经过的时间补充令牌，不超过令牌桶容量
+	if elapsed := now.Sub(bucket.last).Seconds(); elapsed > 0 {
+		bucket.tokens = math.Min(float64(burst), bucket.tokens+elapsed*rate)
+	}
+	bucket.last = now
+
+	result := RateLimitResult{Limit: burst}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - bucket.tokens) / rate)
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = secondsToDuration((float64(burst) - bucket.tokens) / rate)
+	return result, nil
+}
+
+// 返回 key 所在的分片
+func (s *MemoryRateLimitStore) shard(key string) *rateLimitShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return s.shards[h.Sum32()%uint32(len(s.shards))]
+}
+
+// 清理已经恢复满额的令牌桶，调用方需要持有分片的锁
+func (s *rateLimitShard) sweep(now time.Time, rate float64, burst int) {
+	if now.Sub(s.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, bucket := range s.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*rate >= float64(burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// 将秒数转换为时间间隔
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 返回固定结果或错误的存储
+type stubRateLimitStore struct {
+	err error
+}
+
+func (s *stubRateLimitStore) Take(context.Context, string, float64, int) (RateLimitResult, error) {
+	return RateLimitResult{}, s.err
+}
+
+// 创建使用可控时钟的内存存储
+func newTestRateLimitStore(now *time.Time) *MemoryRateLimitStore {
+	store := NewMemoryRateLimitStore(4)
+	store.now = func() time.Time { return *now }
+	return store
+}
+
+func TestMemoryRateLimitStoreRefillsTokens(t *testing.T) {
+	now := time.Unix(1000, 0)
+	store := newTestRateLimitStore(&now)
+
+	for i := 0; i < 2; i++ {
+		result, err := store.Take(context.Background(), "a", 1, 2)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Limit)
+		assert.Equal(t, 1-i, result.Remaining)
+	}
+
+	result, err := store.Take(context.Background(), "a", 1, 2)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 2*time.Second, result.Reset)
+
+	// 其他键使用独立的令牌桶
+	result, _ = store.Take(context.Background(), "b", 1, 2)
+	assert.True(t, result.Allowed)
+
+	now = now.Add(time.Second)
+	result, _ = store.Take(context.Background(), "a", 1, 2)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestMemoryRateLimitStoreSweepsIdleBuckets(t *testing.T) {
+	now := time.Unix(1000, 0)
+	store := NewMemoryRateLimitStore(1)
+	store.now = func() time.Time { return now }
+
+	_, _ = store.Take(context.Background(), "idle", 10, 1)
+	now = now.Add(2 * rateLimitSweepInterval)
+	_, _ = store.Take(context.Background(), "active", 10, 1)
+
+	assert.NotContains(t, store.shards[0].buckets, "idle")
+	assert.Contains(t, store.shards[0].buckets, "active")
+}
+
+func TestRateLimitRejectsWithHeaders(t *testing.T) {
+	router := gin.New()
+	router.GET("/limited", RateLimit(RateLimitConfig{Rate: 0.5, Burst: 1}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := request("10.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "1", recorder.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2", recorder.Header().Get("RateLimit-Reset"))
+
+	recorder = request("10.0.0.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+	var response com.ErrorResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, com.RequestRateLimited, response.Message)
+
+	// 默认按客户端 IP 限流
+	assert.Equal(t, http.StatusOK, request("10.0.0.2:1234").Code)
+}
+
+func TestRateLimitKeyFuncs(t *testing.T) {
+	router := gin.New()
+	router.GET("/header", RateLimit(RateLimitConfig{Rate: 1, Burst: 1, Key: KeyByHeader("X-Api-Key")}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/route/:id", RateLimit(RateLimitConfig{Rate: 1, Burst: 1, Key: KeyByRoute}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(path, apiKey string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if apiKey != "" {
+			req.Header.Set("X-Api-Key", apiKey)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("/header", "a"))
+	assert.Equal(t, http.StatusTooManyRequests, request("/header", "a"))
+	assert.Equal(t, http.StatusOK, request("/header", "b"))
+	// 限流键为空时不限流
+	assert.Equal(t, http.StatusOK, request("/header", ""))
+	assert.Equal(t, http.StatusOK, request("/header", ""))
+
+	// 同一路由模板共享令牌桶
+	assert.Equal(t, http.StatusOK, request("/route/1", ""))
+	assert.Equal(t, http.StatusTooManyRequests, request("/route/2", ""))
+}
+
+func TestRateLimitStoreErrorFailsOpen(t *testing.T) {
+	storeErr := errors.New("store unavailable")
+	var errs []*gin.Error
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		errs = c.Errors
+	})
+	router.GET("/open", RateLimit(RateLimitConfig{Rate: 1, Store: &stubRateLimitStore{err: storeErr}}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/open", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0].Err, storeErr)
+}
+
+func TestRateLimitConcurrent(t *testing.T) {
+	router := gin.New()
+	router.GET("/limited", RateLimit(RateLimitConfig{Rate: 0.001, Burst: 10}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	var mu sync.Mutex
+	allowed := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/limited", nil))
+			if recorder.Code == http.StatusOK {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 10, allowed)
+}
@@ -0,0 +1,234 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+)
+
+// 超时中间件不支持接管连接
+var errHijackUnsupported = errors.New("hijack is not supported by the timeout middleware")
+
+// 服务端推送事件的内容类型
+const eventStreamContentType = "text/event-stream"
+
+// 请求超时时返回的响应体
+var timeoutResponseBody, _ = json.Marshal(com.ErrorResponse{Code: com.RequestErrorCode, Message: com.RequestTimedOut})
+
+// Timeout 返回一个限制请求处理时间的 Gin 中间件，可以用于引擎、路由组或单个路由
+// 请求的 context 会带上截止时间；超时后立即返回 503 JSON 错误，处理函数之后的写入会被丢弃
+// 处理函数的响应在处理结束前被缓存，Flush 不生效且不支持 Hijack，因此协议升级请求（如 WebSocket）
+// 和接受 text/event-stream 的请求不受超时限制，直接交给处理函数；timeout 不大于 0 时不限制
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 || isStreamingRequest(c.Request) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		original := c.Writer
+		writer := newTimeoutWriter(original)
+		c.Writer = writer
+		timer := time.AfterFunc(timeout, writer.timeout)
+
+		completed := false
+		defer func() {
+			timer.Stop()
+			c.Writer = original
+			// 处理函数 panic 时丢弃已缓存的响应，交由外层的恢复中间件处理
+			if !writer.finish(completed, errors.Is(ctx.Err(), context.DeadlineExceeded)) {
+				c.Set(com.RequestTimeoutKey, true)
+				dropTimeoutErrors(c)
+			}
+		}()
+
+		c.Next()
+		completed = true
+	}
+}
+
+// 判断是否为需要流式响应或接管连接的请求：协议升级请求，或者接受 text/event-stream 的请求
+func isStreamingRequest(r *http.Request) bool {
+	if r.Header.Get("Upgrade") != "" {
+		return true
+	}
+	for _, accept := range r.Header.Values("Accept") {
+		if strings.Contains(strings.ToLower(accept), eventStreamContentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// 移除超时后写入失败产生的错误，超时已经单独记录，无需再作为请求错误输出
+func dropTimeoutErrors(c *gin.Context) {
+	errs := c.Errors[:0]
+	for _, err := range c.Errors {
+		if !errors.Is(err.Err, http.ErrHandlerTimeout) {
+			errs = append(errs, err)
+		}
+	}
+	c.Errors = errs
+}
+
+// 缓存处理函数响应的写入器，超时后由计时器直接向原始写入器返回错误响应
+type timeoutWriter struct {
+	gin.ResponseWriter // 原始响应写入器
+
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	status   int
+	written  bool
+	timedOut bool
+	done     bool
+}
+
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{ResponseWriter: w, header: w.Header().Clone()}
+}
+
+// 超时时向原始写入器返回错误响应，请求已处理完成时不做任何操作
+func (w *timeoutWriter) timeout() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		w.writeTimeout()
+	}
+}
+
+// 向原始写入器返回超时错误响应，调用方需要持有锁
+func (w *timeoutWriter) writeTimeout() {
+	w.timedOut = true
+
+	header := w.ResponseWriter.Header()
+	header.Set(com.HttpHeaderContentType, com.HttpHeaderJSONContentTypeValue)
+	header.Set("Content-Length", strconv.Itoa(len(timeoutResponseBody)))
+	w.ResponseWriter.WriteHeader(http.StatusServiceUnavailable)
+	_, _ = w.ResponseWriter.Write(timeoutResponseBody)
+	w.ResponseWriter.Flush()
+}
+
+// 结束缓存并在未超时的情况下将缓存的响应写入原始写入器
+// completed 为 false 表示处理函数没有正常返回，此时丢弃缓存的响应
+// expired 表示请求的截止时间已过，计时器尚未触发时在这里返回超时响应；返回值表示请求是否未超时
+func (w *timeoutWriter) finish(completed, expired bool) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.done = true
+	if !w.timedOut && expired {
+		w.writeTimeout()
+	}
+	if w.timedOut {
+		return false
+	}
+	if !completed {
+		return true
+	}
+
+	header := w.ResponseWriter.Header()
+	for key := range header {
+		delete(header, key)
+	}
+	for key, values := range w.header {
+		header[key] = values
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.written {
+		if w.buf.Len() > 0 {
+			_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+		} else {
+			w.ResponseWriter.WriteHeaderNow()
+		}
+	}
+	return true
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if code > 0 && !w.written && !w.timedOut {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.written = true
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.written = true
+	return w.buf.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.written = true
+	return w.buf.WriteString(s)
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return http.StatusServiceUnavailable
+	}
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.written {
+		return -1
+	}
+	return w.buf.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.written
+}
+
+// 响应在处理结束前被缓存，刷新不做任何操作
+func (w *timeoutWriter) Flush() {}
+
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errHijackUnsupported
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutPassesThroughResponse(t *testing.T) {
+	router := gin.New()
+	router.GET("/fast", Timeout(time.Second), func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		assert.True(t, ok)
+		c.Header("X-Test", "value")
+		c.String(http.StatusCreated, "created")
+	})
+	router.GET("/empty", Timeout(time.Second), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "value", recorder.Header().Get("X-Test"))
+	assert.Equal(t, "created", recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/empty", nil))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+}
+
+func TestTimeoutRespondsWhenDeadlinePasses(t *testing.T) {
+	lateWrite := make(chan error, 1)
+	timedOut := make(chan bool, 1)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		timedOut <- c.GetBool(com.RequestTimeoutKey)
+	})
+	router.GET("/slow", Timeout(20*time.Millisecond), func(c *gin.Context) {
+		time.Sleep(100 * time.Millisecond)
+		c.Header("X-Late", "value")
+		_, err := c.Writer.WriteString("late")
+		lateWrite <- err
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	start := time.Now()
+	resp, err := http.Get(server.URL + "/slow")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	// 超时响应在处理函数返回前到达客户端
+	assert.Less(t, time.Since(start), 90*time.Millisecond)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, com.HttpHeaderJSONContentTypeValue, resp.Header.Get(com.HttpHeaderContentType))
+	assert.Empty(t, resp.Header.Get("X-Late"))
+
+	var response com.ErrorResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	assert.Equal(t, com.RequestErrorCode, response.Code)
+	assert.Equal(t, com.RequestTimedOut, response.Message)
+
+	assert.ErrorIs(t, <-lateWrite, http.ErrHandlerTimeout)
+	assert.True(t, <-timedOut)
+}
+
+func TestTimeoutCancelsRequestContext(t *testing.T) {
+	router := gin.New()
+	router.GET("/wait", Timeout(10*time.Millisecond), func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.String(http.StatusOK, "late")
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/wait", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "late")
+}
+
+func TestTimeoutPanicReachesRecovery(t *testing.T) {
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.GET("/panic", Timeout(time.Second), func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "partial")
+}
+
+func TestTimeoutDisabled(t *testing.T) {
+	router := gin.New()
+	router.GET("/none", Timeout(0), func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		assert.False(t, ok)
+		c.Status(http.StatusOK)
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/none", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestTimeoutSkipsStreamingRequests(t *testing.T) {
+	router := gin.New()
+	router.GET("/stream", Timeout(20*time.Millisecond), func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		assert.False(t, ok)
+		c.Header(com.HttpHeaderContentType, "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("data: one\n\n")
+		c.Writer.Flush()
+		time.Sleep(50 * time.Millisecond)
+		_, _ = c.Writer.WriteString("data: two\n\n")
+	})
+
+	for _, header := range []http.Header{
+		{"Accept": []string{"text/event-stream"}},
+		{"Connection": []string{"Upgrade"}, "Upgrade": []string{"websocket"}},
+	} {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+		req.Header = header
+		router.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.True(t, recorder.Flushed)
+		assert.Equal(t, "data: one\n\ndata: two\n\n", recorder.Body.String())
+	}
+}
@@ -0,0 +1,105 @@
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+)
+
+// MaxLength 是请求 ID 允许的最大长度
+const MaxLength = 128
+
+// Crockford Base32 字母表，用于 ULID 编码
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Generator 生成新的请求 ID
+type Generator func() string
+
+// 返回随机字节，系统随机源不可用时退化为基于时间的填充，保证不会 panic
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		binary.BigEndian.PutUint64(b[len(b)-8:], uint64(time.Now().UnixNano()))
+	}
+}
+
+// 按 8-4-4-4-12 格式编码 UUID
+func formatUUID(u [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// 写入 48 位毫秒时间戳
+func putMillis(b []byte, now time.Time) {
+	ms := uint64(now.UnixMilli())
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+}
+
+// NewUUIDv4 生成随机 UUID（RFC 9562 版本 4）
+func NewUUIDv4() string {
+	var u [16]byte
+	randomBytes(u[:])
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return formatUUID(u)
+}
+
+// NewUUIDv7 生成按时间排序的 UUID（RFC 9562 版本 7）
+func NewUUIDv7() string {
+	var u [16]byte
+	randomBytes(u[6:])
+	putMillis(u[:6], time.Now())
+	u[6] = (u[6] & 0x0f) | 0x70
+	u[8] = (u[8] & 0x3f) | 0x80
+	return formatUUID(u)
+}
+
+// NewULID 生成按时间排序的 ULID，使用 Crockford Base32 编码
+func NewULID() string {
+	var u [16]byte
+	randomBytes(u[6:])
+	putMillis(u[:6], time.Now())
+
+	// 128 位按 5 位一组编码为 26 个字符，首字符只包含最高 3 位
+	var buf [26]byte
+	hi := binary.BigEndian.Uint64(u[:8])
+	lo := binary.BigEndian.Uint64(u[8:])
+	for i := 25; i >= 0; i-- {
+		buf[i] = crockfordAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf[:])
+}
+
+// IsValid 检查请求 ID 是否可以被接受：非空、长度不超过 MaxLength，只包含字母、数字和 - _ . :
+// 不合法的请求 ID 可能来自不可信的客户端，不能直接写入日志和响应头
+func IsValid(id string) bool {
+	if id == "" || len(id) > MaxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
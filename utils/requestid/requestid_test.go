@@ -0,0 +1,55 @@
+package requestid
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	uuidV4Regexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	uuidV7Regexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ulidRegexp   = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+)
+
+func TestGenerators(t *testing.T) {
+	generators := map[string]struct {
+		generate Generator
+		pattern  *regexp.Regexp
+	}{
+		"uuidv4": {NewUUIDv4, uuidV4Regexp},
+		"uuidv7": {NewUUIDv7, uuidV7Regexp},
+		"ulid":   {NewULID, ulidRegexp},
+	}
+	for name, g := range generators {
+		seen := make(map[string]struct{})
+		for i := 0; i < 100; i++ {
+			id := g.generate()
+			assert.Regexp(t, g.pattern, id, name)
+			assert.True(t, IsValid(id), name)
+			assert.NotContains(t, seen, id, name)
+			seen[id] = struct{}{}
+		}
+	}
+}
+
+func TestTimeOrderedGenerators(t *testing.T) {
+	first, firstULID := NewUUIDv7(), NewULID()
+	time.Sleep(2 * time.Millisecond)
+	assert.Less(t, first, NewUUIDv7())
+	assert.Less(t, firstULID, NewULID())
+}
+
+func TestIsValid(t *testing.T) {
+	assert.True(t, IsValid("abc-123_DEF.4:5"))
+	assert.True(t, IsValid(strings.Repeat("a", MaxLength)))
+
+	assert.False(t, IsValid(""))
+	assert.False(t, IsValid(strings.Repeat("a", MaxLength+1)))
+	assert.False(t, IsValid("id with space"))
+	assert.False(t, IsValid("id\nforged-log-line"))
+	assert.False(t, IsValid("<script>"))
+}
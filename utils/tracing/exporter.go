@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// SpanData 是已结束 Span 的只读快照
+type SpanData struct {
+	Name          string         `json:"name"`                    // Span 名称
+	Kind          SpanKind       `json:"kind"`                    // Span 类型
+	Service       string         `json:"service,omitempty"`       // 服务名称
+	TraceID       string         `json:"traceId"`                 // 链路 ID
+	SpanID        string         `json:"spanId"`                  // Span ID
+	ParentSpanID  string         `json:"parentSpanId,omitempty"`  // 父 Span ID，根 Span 为空
+	TraceState    string         `json:"traceState,omitempty"`    // 传播的 tracestate
+	StartTime     time.Time      `json:"startTime"`               // 开始时间
+	EndTime       time.Time      `json:"endTime"`                 // 结束时间
+	DurationMs    float64        `json:"durationMs"`              // 耗时（毫秒）
+	Status        StatusCode     `json:"status"`                  // 结果
+	StatusMessage string         `json:"statusMessage,omitempty"` // 结果说明
+	Attributes    map[string]any `json:"attributes,omitempty"`    // 属性
+}
+
+// Exporter 导出已结束的 Span，Span 结束时被同步调用，实现需要保证并发安全
+// 需要批量或异步发送到远端时，由实现自行缓冲
+type Exporter interface {
+	Export(span SpanData) error
+}
+
+// JSONLExporter 将 Span 以每行一个 JSON 对象的格式写入 io.Writer
+type JSONLExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLExporter 创建写入 w 的 JSONLExporter
+func NewJSONLExporter(w io.Writer) *JSONLExporter {
+	return &JSONLExporter{w: w}
+}
+
+// NewStdoutExporter 创建写入标准输出的 JSONLExporter
+func NewStdoutExporter() *JSONLExporter {
+	return NewJSONLExporter(os.Stdout)
+}
+
+// Export 将 Span 编码为一行 JSON 写入
+func (e *JSONLExporter) Export(span SpanData) error {
+	line, err := json.Marshal(span)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.w.Write(line)
+	return err
+}
+
+// MemoryExporter 将 Span 保存在内存中，用于测试和调试
+type MemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// NewMemoryExporter 创建 MemoryExporter
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{}
+}
+
+// Export 保存 Span
+func (e *MemoryExporter) Export(span SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+	return nil
+}
+
+// Spans 返回已导出的 Span，按结束顺序排列
+func (e *MemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	spans := make([]SpanData, len(e.spans))
+	copy(spans, e.spans)
+	return spans
+}
+
+// Reset 清空已导出的 Span
+func (e *MemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
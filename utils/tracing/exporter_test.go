@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLExporter(t *testing.T) {
+	var buf bytes.Buffer
+	exporter := NewJSONLExporter(&buf)
+	require.NoError(t, exporter.Export(SpanData{Name: "first", TraceID: "t1", SpanID: "s1"}))
+	require.NoError(t, exporter.Export(SpanData{Name: "second", TraceID: "t1", SpanID: "s2", ParentSpanID: "s1"}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var span SpanData
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &span))
+	assert.Equal(t, "second", span.Name)
+	assert.Equal(t, "s1", span.ParentSpanID)
+}
+
+func TestMemoryExporter(t *testing.T) {
+	exporter := NewMemoryExporter()
+	require.NoError(t, exporter.Export(SpanData{Name: "op"}))
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "op", spans[0].Name)
+
+	exporter.Reset()
+	assert.Empty(t, exporter.Spans())
+}
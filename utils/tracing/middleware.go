@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/shengyanli1982/orbit/utils/middleware"
+)
+
+// Span 属性名称
+const (
+	AttrHTTPMethod     = "http.method"
+	AttrHTTPRoute      = "http.route"
+	AttrHTTPTarget     = "http.target"
+	AttrHTTPStatusCode = "http.status_code"
+	AttrHTTPLatencyMs  = "http.latency_ms"
+	AttrService        = "orbit.service"
+)
+
+// Middleware 返回一个为每个请求创建服务端 Span 的 Gin 中间件
+// 请求携带合法的 traceparent 时延续上游链路，否则开始一条新链路；健康检查、指标等资源路径不创建 Span
+// Span 保存在请求的 context 中，处理函数可以通过 StartSpan(c.Request.Context(), name) 创建子 Span
+func Middleware(tracer *Tracer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tracer == nil || middleware.SkipResources(c) {
+			c.Next()
+			return
+		}
+
+		req := c.Request
+		route := c.FullPath()
+		name := req.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		parent, _ := Extract(req.Header)
+		ctx, span := tracer.start(req.Context(), name, SpanKindServer, parent)
+		c.Request = req.WithContext(ctx)
+
+		sc := span.SpanContext()
+		c.Set(com.RequestTraceIDKey, sc.TraceID.String())
+		c.Set(com.RequestSpanIDKey, sc.SpanID.String())
+		c.Set(com.RequestTraceSampledKey, sc.Sampled)
+
+		start := time.Now()
+		completed := false
+		defer func() {
+			status := c.Writer.Status()
+			span.SetAttribute(AttrHTTPMethod, req.Method)
+			span.SetAttribute(AttrHTTPRoute, route)
+			span.SetAttribute(AttrHTTPTarget, req.URL.Path)
+			span.SetAttribute(AttrHTTPStatusCode, status)
+			span.SetAttribute(AttrHTTPLatencyMs, float64(time.Since(start).Microseconds())/1000)
+			if service := c.GetString(com.RequestServiceKey); service != "" {
+				span.SetAttribute(AttrService, service)
+			}
+			switch {
+			case !completed:
+				// 处理函数 panic，由外层的恢复中间件返回 500
+				span.SetAttribute(AttrHTTPStatusCode, http.StatusInternalServerError)
+				span.SetStatus(StatusError, "panic")
+			case status >= http.StatusInternalServerError:
+				span.SetStatus(StatusError, http.StatusText(status))
+			}
+			span.End()
+		}()
+
+		c.Next()
+		completed = true
+	}
+}
+
+// TraceIDFromContext 返回 gin.Context 中当前请求的链路 ID，未启用链路追踪时返回空字符串
+func TraceIDFromContext(c *gin.Context) string {
+	return c.GetString(com.RequestTraceIDKey)
+}
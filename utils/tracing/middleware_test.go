@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTracedRouter(exporter Exporter) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.CustomRecovery(func(c *gin.Context, _ any) { c.AbortWithStatus(http.StatusInternalServerError) }))
+	router.Use(Middleware(NewTracer("orders", exporter)))
+	router.GET("/orders/:id", func(c *gin.Context) {
+		_, span := StartSpan(c.Request.Context(), "load order")
+		span.End()
+		c.String(http.StatusOK, TraceIDFromContext(c))
+	})
+	router.GET("/fail", func(c *gin.Context) { c.Status(http.StatusBadGateway) })
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+	router.GET(com.HealthCheckURLPath, func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func TestMiddlewareContinuesIncomingTrace(t *testing.T) {
+	exporter := NewMemoryExporter()
+	router := newTracedRouter(exporter)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/42", nil)
+	req.Header.Set(TraceparentHeader, testTraceparent)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", recorder.Body.String())
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 2)
+	child, server := spans[0], spans[1]
+	assert.Equal(t, "GET /orders/:id", server.Name)
+	assert.Equal(t, SpanKindServer, server.Kind)
+	assert.Equal(t, "00f067aa0ba902b7", server.ParentSpanID)
+	assert.Equal(t, server.SpanID, child.ParentSpanID)
+	assert.Equal(t, server.TraceID, child.TraceID)
+	assert.Equal(t, "/orders/:id", server.Attributes[AttrHTTPRoute])
+	assert.Equal(t, "/orders/42", server.Attributes[AttrHTTPTarget])
+	assert.Equal(t, http.StatusOK, server.Attributes[AttrHTTPStatusCode])
+	assert.Contains(t, server.Attributes, AttrHTTPLatencyMs)
+	assert.Equal(t, StatusUnset, server.Status)
+}
+
+func TestMiddlewareStartsNewTrace(t *testing.T) {
+	exporter := NewMemoryExporter()
+	router := newTracedRouter(exporter)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/42", nil)
+	req.Header.Set(TraceparentHeader, "invalid")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 2)
+	assert.Empty(t, spans[1].ParentSpanID)
+	assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[1].TraceID)
+}
+
+func TestMiddlewareSetsSampledFlag(t *testing.T) {
+	sampled := make(map[string]bool)
+	for _, traceparent := range []string{testTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"} {
+		router := gin.New()
+		router.Use(Middleware(NewTracer("orders", NewMemoryExporter())))
+		router.GET("/orders", func(c *gin.Context) { sampled[traceparent] = c.GetBool(com.RequestTraceSampledKey) })
+
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		req.Header.Set(TraceparentHeader, traceparent)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	assert.True(t, sampled[testTraceparent])
+	assert.False(t, sampled["00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"])
+}
+
+func TestMiddlewareErrorStatus(t *testing.T) {
+	exporter := NewMemoryExporter()
+	router := newTracedRouter(exporter)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, StatusError, spans[0].Status)
+	assert.Equal(t, http.StatusBadGateway, spans[0].Attributes[AttrHTTPStatusCode])
+	assert.Equal(t, StatusError, spans[1].Status)
+	assert.Equal(t, http.StatusInternalServerError, spans[1].Attributes[AttrHTTPStatusCode])
+}
+
+func TestMiddlewareSkipsResources(t *testing.T) {
+	exporter := NewMemoryExporter()
+	router := newTracedRouter(exporter)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, com.HealthCheckURLPath, nil))
+	assert.Empty(t, exporter.Spans())
+}
@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// W3C Trace Context 请求头
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+// tracestate 允许的最大长度，超过时丢弃
+const maxTracestateLength = 512
+
+// traceparent 的采样标志位
+const flagSampled byte = 0x01
+
+// ErrInvalidTraceparent 表示 traceparent 请求头格式不合法
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// TraceID 是 16 字节的链路 ID
+type TraceID [16]byte
+
+// IsValid 返回链路 ID 是否有效（不全为 0）
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// String 返回链路 ID 的十六进制表示
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID 是 8 字节的 Span ID
+type SpanID [8]byte
+
+// IsValid 返回 Span ID 是否有效（不全为 0）
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// String 返回 Span ID 的十六进制表示
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanContext 描述在服务之间传播的链路信息
+type SpanContext struct {
+	TraceID    TraceID // 链路 ID
+	SpanID     SpanID  // Span ID
+	Sampled    bool    // 是否采样
+	TraceState string  // 供应商相关的 tracestate，原样传播
+	Remote     bool    // 是否从请求头中解析得到
+}
+
+// IsValid 返回链路信息是否有效
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent 返回 traceparent 请求头的值
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent 解析 traceparent 请求头，格式为 version-traceid-spanid-flags
+// 未知的高版本按 00 版本的前缀解析，版本 ff 以及全为 0 的链路 ID 或 Span ID 不合法
+func ParseTraceparent(value string) (SpanContext, error) {
+	var sc SpanContext
+	value = strings.TrimSpace(value)
+	if len(value) < 55 || value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return sc, ErrInvalidTraceparent
+	}
+
+	version, ok := decodeLowerHex(value[0:2])
+	if !ok || version[0] == 0xff {
+		return sc, ErrInvalidTraceparent
+	}
+	// 00 版本的长度固定，更高版本允许在后面追加以 - 分隔的字段
+	if (version[0] == 0 && len(value) != 55) || (len(value) > 55 && value[55] != '-') {
+		return sc, ErrInvalidTraceparent
+	}
+
+	traceID, ok := decodeLowerHex(value[3:35])
+	if !ok {
+		return sc, ErrInvalidTraceparent
+	}
+	spanID, ok := decodeLowerHex(value[36:52])
+	if !ok {
+		return sc, ErrInvalidTraceparent
+	}
+	flags, ok := decodeLowerHex(value[53:55])
+	if !ok {
+		return sc, ErrInvalidTraceparent
+	}
+
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	sc.Sampled = flags[0]&flagSampled != 0
+	sc.Remote = true
+	return sc, nil
+}
+
+// 解码小写的十六进制字符串，W3C 规范不允许大写
+func decodeLowerHex(s string) ([]byte, bool) {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return nil, false
+		}
+	}
+	b, err := hex.DecodeString(s)
+	return b, err == nil
+}
+
+// Extract 从请求头中解析上游传入的链路信息，traceparent 缺失或不合法时返回 false
+func Extract(header http.Header) (SpanContext, bool) {
+	sc, err := ParseTraceparent(header.Get(TraceparentHeader))
+	if err != nil {
+		return SpanContext{}, false
+	}
+	// 多个 tracestate 请求头按顺序合并
+	if state := strings.Join(header.Values(TracestateHeader), ","); len(state) <= maxTracestateLength {
+		sc.TraceState = strings.TrimSpace(state)
+	}
+	return sc, true
+}
+
+// Inject 将链路信息写入请求头，用于向下游服务传播
+func Inject(sc SpanContext, header http.Header) {
+	if !sc.IsValid() {
+		return
+	}
+	header.Set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		header.Set(TracestateHeader, sc.TraceState)
+	} else {
+		header.Del(TracestateHeader)
+	}
+}
@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent(testTraceparent)
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled)
+	assert.True(t, sc.Remote)
+	assert.Equal(t, testTraceparent, sc.Traceparent())
+
+	sc, err = ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.NoError(t, err)
+	assert.False(t, sc.Sampled)
+
+	// 更高版本允许追加字段
+	sc, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	require.NoError(t, err)
+	assert.True(t, sc.IsValid())
+}
+
+func TestParseTraceparentInvalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		_, err := ParseTraceparent(value)
+		assert.ErrorIs(t, err, ErrInvalidTraceparent, value)
+	}
+}
+
+func TestExtractInject(t *testing.T) {
+	header := http.Header{}
+	header.Set(TraceparentHeader, testTraceparent)
+	header.Add(TracestateHeader, "vendor1=a")
+	header.Add(TracestateHeader, "vendor2=b")
+
+	sc, ok := Extract(header)
+	require.True(t, ok)
+	assert.Equal(t, "vendor1=a,vendor2=b", sc.TraceState)
+
+	out := http.Header{}
+	Inject(sc, out)
+	assert.Equal(t, testTraceparent, out.Get(TraceparentHeader))
+	assert.Equal(t, "vendor1=a,vendor2=b", out.Get(TracestateHeader))
+
+	// 无效的链路信息不会写入请求头
+	out = http.Header{}
+	Inject(SpanContext{}, out)
+	assert.Empty(t, out)
+
+	_, ok = Extract(http.Header{})
+	assert.False(t, ok)
+}
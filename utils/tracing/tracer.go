@@ -0,0 +1,284 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	com "github.com/shengyanli1982/orbit/common"
+)
+
+// SpanKind 描述 Span 在调用关系中的角色
+type SpanKind string
+
+// Span 类型
+const (
+	SpanKindServer   SpanKind = "server"   // 处理传入的请求
+	SpanKindClient   SpanKind = "client"   // 发起对下游的请求
+	SpanKindInternal SpanKind = "internal" // 进程内的操作
+)
+
+// StatusCode 描述 Span 的结果
+type StatusCode string
+
+// Span 状态
+const (
+	StatusUnset StatusCode = "unset" // 未设置
+	StatusOK    StatusCode = "ok"    // 成功
+	StatusError StatusCode = "error" // 失败
+)
+
+// 保存 Span 的 context 键
+type spanContextKey struct{}
+
+// Tracer 创建 Span 并在 Span 结束时通过 Exporter 导出
+type Tracer struct {
+	service     string
+	exporter    Exporter
+	sampleRatio float64
+	logger      *logr.Logger
+}
+
+// NewTracer 创建 Tracer，service 为导出的 Span 中的服务名称，exporter 为 nil 时不导出 Span
+func NewTracer(service string, exporter Exporter) *Tracer {
+	return &Tracer{service: service, exporter: exporter, sampleRatio: 1, logger: &com.DefaultLogrLogger}
+}
+
+// WithSampleRatio 设置新链路的采样比例，取值 [0, 1]，默认为 1
+// 传入的链路沿用上游的采样决定；未被采样的 Span 仍然传播链路信息，但不会被导出
+func (t *Tracer) WithSampleRatio(ratio float64) *Tracer {
+	t.sampleRatio = math.Max(0, math.Min(1, ratio))
+	return t
+}
+
+// WithLogger 设置记录导出失败的日志记录器
+func (t *Tracer) WithLogger(logger *logr.Logger) *Tracer {
+	if logger != nil {
+		t.logger = logger
+	}
+	return t
+}
+
+// Start 创建一个 Span，ctx 中存在 Span 时作为其子 Span，否则开始一条新链路
+// 返回的 context 携带新的 Span，调用方需要在操作结束时调用 Span.End
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	var parent SpanContext
+	if span := SpanFromContext(ctx); span != nil {
+		parent = span.SpanContext()
+	}
+	return t.start(ctx, name, kind, parent)
+}
+
+// 以 parent 为父链路创建 Span，parent 无效时开始一条新链路
+func (t *Tracer) start(ctx context.Context, name string, kind SpanKind, parent SpanContext) (context.Context, *Span) {
+	span := &Span{
+		tracer:     t,
+		name:       name,
+		kind:       kind,
+		start:      time.Now(),
+		status:     StatusUnset,
+		attributes: make(map[string]any),
+	}
+
+	if parent.IsValid() {
+		span.sc = SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled, TraceState: parent.TraceState}
+		span.parent = parent.SpanID
+	} else {
+		randomRead(span.sc.TraceID[:])
+		span.sc.Sampled = t.shouldSample(span.sc.TraceID)
+	}
+	randomRead(span.sc.SpanID[:])
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// 按链路 ID 的低 8 字节决定是否采样，同一链路的决定保持一致
+func (t *Tracer) shouldSample(id TraceID) bool {
+	if t.sampleRatio >= 1 {
+		return true
+	}
+	return float64(binary.BigEndian.Uint64(id[8:])) < t.sampleRatio*math.MaxUint64
+}
+
+// 导出已结束的 Span
+func (t *Tracer) export(data SpanData) {
+	if t.exporter == nil {
+		return
+	}
+	if err := t.exporter.Export(data); err != nil {
+		t.logger.Error(err, "failed to export span", "traceId", data.TraceID, "spanId", data.SpanID)
+	}
+}
+
+// 读取随机字节，系统随机源不可用时退化为基于时间的填充
+func randomRead(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		binary.BigEndian.PutUint64(b[len(b)-8:], uint64(time.Now().UnixNano()))
+	}
+	// 全为 0 的 ID 不合法
+	for _, v := range b {
+		if v != 0 {
+			return
+		}
+	}
+	b[len(b)-1] = 1
+}
+
+// Span 记录一次操作的耗时、属性和结果，可以被多个 goroutine 并发使用
+type Span struct {
+	tracer *Tracer
+	name   string
+	kind   SpanKind
+	sc     SpanContext
+	parent SpanID
+	start  time.Time
+
+	mu            sync.Mutex
+	attributes    map[string]any
+	status        StatusCode
+	statusMessage string
+	ended         bool
+}
+
+// SpanContext 返回 Span 的链路信息，用于向下游传播
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// TraceID 返回 Span 所在链路的 ID
+func (s *Span) TraceID() TraceID {
+	return s.SpanContext().TraceID
+}
+
+// IsRecording 返回 Span 是否会被导出
+func (s *Span) IsRecording() bool {
+	return s != nil && s.tracer != nil && s.sc.Sampled
+}
+
+// SetName 修改 Span 的名称
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+// SetAttribute 设置 Span 的属性
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.attributes[key] = value
+	}
+}
+
+// SetStatus 设置 Span 的结果
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.status = code
+		s.statusMessage = message
+	}
+}
+
+// RecordError 记录错误并将 Span 标记为失败
+func (s *Span) RecordError(err error) {
+	if err != nil {
+		s.SetStatus(StatusError, err.Error())
+	}
+}
+
+// End 结束 Span，被采样的 Span 会被导出，重复调用不会重复导出
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	end := time.Now()
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	data := s.snapshot(end)
+	s.mu.Unlock()
+
+	if s.IsRecording() {
+		s.tracer.export(data)
+	}
+}
+
+// 生成 Span 的只读快照，调用方需要持有锁
+func (s *Span) snapshot(end time.Time) SpanData {
+	data := SpanData{
+		Name:          s.name,
+		Kind:          s.kind,
+		TraceID:       s.sc.TraceID.String(),
+		SpanID:        s.sc.SpanID.String(),
+		TraceState:    s.sc.TraceState,
+		StartTime:     s.start,
+		EndTime:       end,
+		DurationMs:    float64(end.Sub(s.start).Microseconds()) / 1000,
+		Status:        s.status,
+		StatusMessage: s.statusMessage,
+		Attributes:    make(map[string]any, len(s.attributes)),
+	}
+	if s.tracer != nil {
+		data.Service = s.tracer.service
+	}
+	if s.parent.IsValid() {
+		data.ParentSpanID = s.parent.String()
+	}
+	for key, value := range s.attributes {
+		data.Attributes[key] = value
+	}
+	return data
+}
+
+// ContextWithSpan 返回携带 span 的 context
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// SpanFromContext 返回 context 中的 Span，不存在时返回 nil
+// nil Span 的所有方法都可以安全调用
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// StartSpan 使用 ctx 中 Span 所属的 Tracer 创建子 Span
+// ctx 中没有 Span（例如未启用链路追踪）时返回原 context 和 nil Span，调用方无需判断即可使用
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil || parent.tracer == nil {
+		return ctx, nil
+	}
+	return parent.tracer.start(ctx, name, SpanKindInternal, parent.sc)
+}
+
+// InjectContext 将 ctx 中 Span 的链路信息写入请求头，用于调用下游服务
+func InjectContext(ctx context.Context, header http.Header) {
+	Inject(SpanFromContext(ctx).SpanContext(), header)
+}
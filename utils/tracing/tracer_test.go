@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracerStartChildSpan(t *testing.T) {
+	exporter := NewMemoryExporter()
+	tracer := NewTracer("orders", exporter)
+
+	ctx, root := tracer.Start(context.Background(), "root", SpanKindServer)
+	childCtx, child := StartSpan(ctx, "child")
+	child.SetAttribute("db.table", "orders")
+	child.RecordError(errors.New("boom"))
+	child.End()
+	root.End()
+	root.End()
+
+	assert.Same(t, child, SpanFromContext(childCtx))
+	spans := exporter.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, SpanKindInternal, spans[0].Kind)
+	assert.Equal(t, root.TraceID().String(), spans[0].TraceID)
+	assert.Equal(t, root.SpanContext().SpanID.String(), spans[0].ParentSpanID)
+	assert.Equal(t, "orders", spans[0].Attributes["db.table"])
+	assert.Equal(t, StatusError, spans[0].Status)
+	assert.Equal(t, "boom", spans[0].StatusMessage)
+	assert.Equal(t, "orders", spans[1].Service)
+	assert.Empty(t, spans[1].ParentSpanID)
+
+	// 结束后的修改不会生效
+	root.SetAttribute("late", true)
+	assert.NotContains(t, exporter.Spans()[1].Attributes, "late")
+}
+
+func TestTracerRemoteParent(t *testing.T) {
+	exporter := NewMemoryExporter()
+	tracer := NewTracer("orders", exporter)
+	parent, err := ParseTraceparent(testTraceparent)
+	require.NoError(t, err)
+	parent.TraceState = "vendor=a"
+
+	_, span := tracer.start(context.Background(), "server", SpanKindServer, parent)
+	span.End()
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, parent.TraceID.String(), spans[0].TraceID)
+	assert.Equal(t, parent.SpanID.String(), spans[0].ParentSpanID)
+	assert.NotEqual(t, parent.SpanID.String(), spans[0].SpanID)
+	assert.Equal(t, "vendor=a", spans[0].TraceState)
+}
+
+func TestTracerSampling(t *testing.T) {
+	exporter := NewMemoryExporter()
+	tracer := NewTracer("orders", exporter).WithSampleRatio(0)
+
+	ctx, span := tracer.Start(context.Background(), "root", SpanKindServer)
+	assert.False(t, span.IsRecording())
+	assert.True(t, span.SpanContext().IsValid())
+	_, child := StartSpan(ctx, "child")
+	assert.Equal(t, span.TraceID(), child.TraceID())
+	child.End()
+	span.End()
+	assert.Empty(t, exporter.Spans())
+
+	// 上游已采样的链路沿用上游的决定
+	parent, err := ParseTraceparent(testTraceparent)
+	require.NoError(t, err)
+	_, span = tracer.start(context.Background(), "server", SpanKindServer, parent)
+	span.End()
+	assert.Len(t, exporter.Spans(), 1)
+}
+
+func TestStartSpanWithoutTracer(t *testing.T) {
+	ctx, span := StartSpan(context.Background(), "op")
+	assert.Nil(t, span)
+	assert.Equal(t, context.Background(), ctx)
+
+	// nil Span 的方法可以安全调用
+	span.SetAttribute("key", "value")
+	span.RecordError(errors.New("boom"))
+	span.End()
+	assert.False(t, span.IsRecording())
+
+	header := http.Header{}
+	InjectContext(ctx, header)
+	assert.Empty(t, header)
+}
+
+func TestInjectContext(t *testing.T) {
+	ctx, span := NewTracer("orders", nil).Start(context.Background(), "client", SpanKindClient)
+	defer span.End()
+
+	header := http.Header{}
+	InjectContext(ctx, header)
+	sc, ok := Extract(header)
+	require.True(t, ok)
+	assert.Equal(t, span.TraceID(), sc.TraceID)
+	assert.Equal(t, span.SpanContext().SpanID, sc.SpanID)
+}
@@ -0,0 +1,230 @@
+package orbit
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	"github.com/shengyanli1982/orbit/internal/tlsutil"
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrInvalidConfig 表示配置校验失败，Validate 返回的每一个问题都包装了该错误
+var ErrInvalidConfig = errors.New("invalid config")
+
+// 支持的真实客户端 IP 解析头
+var knownRemoteIPHeaders = map[string]struct{}{
+	"X-Forwarded-For":          {},
+	"X-Real-Ip":                {},
+	"X-Client-Ip":              {},
+	"X-Cluster-Client-Ip":      {},
+	"X-Original-Forwarded-For": {},
+	"Cf-Connecting-Ip":         {},
+	"True-Client-Ip":           {},
+	"Fastly-Client-Ip":         {},
+}
+
+// Validate 校验配置，一次性返回所有问题，配置有效时返回 nil
+// 未设置的字段（零值）会在创建引擎时使用默认值，不视为错误；Port 例外，为 0 且未设置 EphemeralPort 时视为错误
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	var errs configErrors
+	c.validateStaticFields(errs.invalid)
+	c.validateLiveFields(errs.invalid)
+	return errors.Join(errs...)
+}
+
+// 校验可以在运行时热更新的字段，ApplyConfig 只校验这些字段
+func (c *Config) validateLive() error {
+	if c == nil {
+		return nil
+	}
+
+	var errs configErrors
+	c.validateLiveFields(errs.invalid)
+	return errors.Join(errs...)
+}
+
+// 汇总的配置校验错误
+type configErrors []error
+
+// 记录一个配置问题，错误包装了 ErrInvalidConfig
+func (e *configErrors) invalid(format string, args ...any) {
+	*e = append(*e, fmt.Errorf("%w: "+format, append([]any{ErrInvalidConfig}, args...)...))
+}
+
+// 校验需要重启引擎才能生效的字段
+func (c *Config) validateStaticFields(invalid func(format string, args ...any)) {
+	// 监听地址
+	if (c.Port == 0 || c.portDefaulted) && !c.EphemeralPort && c.listensOnPort() {
+		invalid("port 0 is replaced with the default port %d, use ephemeralPort to let the system choose a port", defaultHttpListenPort)
+	}
+	if c.Address != "" && !isValidHost(c.Address) {
+		invalid("address %q is not a valid IP or host name", c.Address)
+	}
+	if c.AdminAddress != "" && !isValidHost(c.AdminAddress) {
+		invalid("adminAddress %q is not a valid IP or host name", c.AdminAddress)
+	}
+	if c.IsAdminEnabled() && c.UnixSocket == "" && !c.EphemeralPort && c.Port != 0 && c.AdminPort == c.Port &&
+		isAddressOverlapped(c.Address, c.AdminAddress) {
+		invalid("adminPort %d conflicts with port", c.AdminPort)
+	}
+
+	// 超时时间
+	if c.HttpReadTimeout > 0 && c.HttpReadHeaderTimeout > c.HttpReadTimeout {
+		invalid("httpReadHeaderTimeout %d is larger than httpReadTimeout %d", c.HttpReadHeaderTimeout, c.HttpReadTimeout)
+	}
+	if c.HttpWriteTimeout > 0 && c.HttpRequestTimeout >= c.HttpWriteTimeout {
+		invalid("httpRequestTimeout %d must be smaller than httpWriteTimeout %d", c.HttpRequestTimeout, c.HttpWriteTimeout)
+	}
+
+	// 度量标准命名
+	if c.MetricNamespace != "" && !isValidMetricName(c.MetricNamespace) {
+		invalid("metricNamespace %q is not a valid Prometheus metric name", c.MetricNamespace)
+	}
+	if c.MetricSubsystem != "" && !isValidMetricName(c.MetricSubsystem) {
+		invalid("metricSubsystem %q is not a valid Prometheus metric name", c.MetricSubsystem)
+	}
+
+	// TLS
+	if t := c.TLS; t != nil {
+		if (t.CertFile == "") != (t.KeyFile == "") {
+			invalid("tls certFile and keyFile must be set together")
+		}
+		if t.MinVersion != "" {
+			if _, err := tlsutil.ParseMinVersion(t.MinVersion); err != nil {
+				invalid("tls minVersion: %v", err)
+			}
+		}
+		if _, err := tlsutil.ParseCipherSuites(t.CipherSuites); err != nil {
+			invalid("tls cipherSuites: %v", err)
+		}
+		if _, err := tlsutil.ParseClientAuth(t.ClientAuth); err != nil {
+			invalid("tls clientAuth: %v", err)
+		} else if t.IsClientAuthEnabled() && t.ClientCAFile == "" {
+			invalid("tls clientAuth %q requires clientCAFile", t.ClientAuth)
+		}
+	}
+}
+
+// 校验可以在运行时热更新的字段
+func (c *Config) validateLiveFields(invalid func(format string, args ...any)) {
+	// 代理与客户端 IP 解析
+	for i, proxy := range c.TrustedProxies {
+		if !isValidIPOrCIDR(proxy) {
+			invalid("trustedProxies[%d] %q is not a valid IP or CIDR", i, proxy)
+		}
+	}
+	for i, header := range c.RemoteIPHeaders {
+		if _, ok := knownRemoteIPHeaders[textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(header))]; !ok {
+			invalid("remoteIPHeaders[%d] %q is not a known client IP header", i, header)
+		}
+	}
+
+	// 日志级别
+	if c.LogLevel != "" {
+		if _, err := zapcore.ParseLevel(c.LogLevel); err != nil {
+			invalid("logLevel %q is not a valid level", c.LogLevel)
+		}
+	}
+
+	// CORS 策略
+	if p := c.CORSPolicy; p != nil && p.Enabled {
+		if p.AllowCredentials && p.AllowAllOrigins {
+			invalid("corsPolicy allowCredentials can not be combined with allowAllOrigins")
+		}
+		for i, origin := range p.AllowedOrigins {
+			if origin == "*" {
+				invalid("corsPolicy allowedOrigins[%d] \"*\" should be set with allowAllOrigins", i)
+			}
+		}
+	}
+
+	// 访问日志策略
+	if p := c.AccessLogPolicy; p != nil {
+		if p.SampleRate != nil && (*p.SampleRate < 0 || *p.SampleRate > 1) {
+			invalid("accessLogPolicy sampleRate %v must be between 0 and 1", *p.SampleRate)
+		}
+		routes := make([]string, 0, len(p.RouteSampleRates))
+		for route := range p.RouteSampleRates {
+			routes = append(routes, route)
+		}
+		sort.Strings(routes)
+		for _, route := range routes {
+			if rate := p.RouteSampleRates[route]; rate < 0 || rate > 1 {
+				invalid("accessLogPolicy routeSampleRates[%q] %v must be between 0 and 1", route, rate)
+			}
+		}
+		for i, method := range p.SkipMethods {
+			if strings.TrimSpace(method) == "" {
+				invalid("accessLogPolicy skipMethods[%d] is empty", i)
+			}
+		}
+		for i, path := range p.SkipPaths {
+			if !strings.HasPrefix(path, "/") {
+				invalid("accessLogPolicy skipPaths[%d] %q must start with /", i, path)
+			}
+		}
+	}
+}
+
+// 判断是否为合法的 IP 地址或主机名
+func isValidHost(host string) bool {
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	if len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			ch := label[i]
+			if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// 判断两个监听地址在同一端口上是否冲突，空地址与通配地址与任意地址冲突
+func isAddressOverlapped(left, right string) bool {
+	if left == "" || right == "" || left == right {
+		return true
+	}
+	for _, addr := range []string{left, right} {
+		if ip := net.ParseIP(addr); ip != nil && ip.IsUnspecified() {
+			return true
+		}
+	}
+	return false
+}
+
+// 判断是否为合法的 Prometheus 度量标准名称片段
+func isValidMetricName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_' || ch >= '0' && ch <= '9' && i > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// 判断是否为合法的 IP 地址或 CIDR
+func isValidIPOrCIDR(value string) bool {
+	if net.ParseIP(value) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
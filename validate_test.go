@@ -0,0 +1,134 @@
+package orbit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	com "github.com/shengyanli1982/orbit/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidateDefaults(t *testing.T) {
+	assert.NoError(t, NewConfig().Validate())
+	assert.NoError(t, DefaultConfig().Validate())
+	assert.NoError(t, NewConfig().WithAddress("localhost").WithAdminAddress("::1").WithAdminPort(8081).Validate())
+	assert.NoError(t, NewConfig().WithTrustedProxies([]string{"10.0.0.1", "192.168.0.0/16", "fd00::/8"}).Validate())
+	assert.NoError(t, NewConfig().WithRemoteIPHeaders([]string{"x-real-ip", "CF-Connecting-IP"}).Validate())
+}
+
+func TestConfigValidateAggregatesErrors(t *testing.T) {
+	policy := *NewConfig().CORSPolicy
+	policy.AllowCredentials = true
+
+	config := NewConfig().
+		WithAddress("bad host!").
+		WithTrustedProxies([]string{"10.0.0.0/8", "invalid-cidr", "300.0.0.1"}).
+		WithHttpReadTimeout(1000).
+		WithHttpReadHeaderTimeout(2000).
+		WithHttpWriteTimeout(3000).
+		WithHttpRequestTimeout(3000).
+		WithCORSPolicy(policy).
+		WithRemoteIPHeaders([]string{"X-Forwarded-For", "X-Unknown"})
+
+	err := config.Validate()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	var joined interface{ Unwrap() []error }
+	require.True(t, errors.As(err, &joined))
+	assert.Len(t, joined.Unwrap(), 7)
+
+	msg := err.Error()
+	assert.Contains(t, msg, `address "bad host!"`)
+	assert.Contains(t, msg, `trustedProxies[1] "invalid-cidr"`)
+	assert.Contains(t, msg, `trustedProxies[2] "300.0.0.1"`)
+	assert.Contains(t, msg, "httpReadHeaderTimeout 2000 is larger than httpReadTimeout 1000")
+	assert.Contains(t, msg, "httpRequestTimeout 3000 must be smaller than httpWriteTimeout 3000")
+	assert.Contains(t, msg, "allowCredentials can not be combined with allowAllOrigins")
+	assert.Contains(t, msg, `remoteIPHeaders[1] "X-Unknown"`)
+}
+
+func TestConfigValidateAdminPortConflict(t *testing.T) {
+	err := NewConfig().WithPort(8080).WithAdminPort(8080).Validate()
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorIs(t, NewConfig().WithAddress("0.0.0.0").WithPort(8080).WithAdminAddress("127.0.0.1").WithAdminPort(8080).Validate(), ErrInvalidConfig)
+
+	assert.NoError(t, NewConfig().WithPort(8080).WithAddress("127.0.0.1").WithAdminAddress("127.0.0.2").WithAdminPort(8080).Validate())
+}
+
+func TestConfigValidatePortZero(t *testing.T) {
+	// 端口 0 会被替换为默认端口，需要由系统分配端口时应使用 EphemeralPort
+	assert.ErrorContains(t, NewConfig().WithPort(0).Validate(), "port 0 is replaced with the default port 8080")
+	assert.ErrorIs(t, isConfigValid(NewConfig().WithPort(0)).Validate(), ErrInvalidConfig)
+	assert.NoError(t, NewConfig().WithEphemeralPort().Validate())
+	assert.NoError(t, NewConfig().WithPort(0).WithUnixSocket("/tmp/orbit.sock", 0, "", "").Validate())
+}
+
+func TestConfigValidateTLS(t *testing.T) {
+	err := NewConfig().WithTLS(com.TLSConfig{
+		CertFile:     "server.crt",
+		MinVersion:   "2.0",
+		CipherSuites: []string{"UNKNOWN_SUITE"},
+		ClientAuth:   "sometimes",
+	}).Validate()
+	require.Error(t, err)
+
+	msg := err.Error()
+	assert.Contains(t, msg, "certFile and keyFile must be set together")
+	assert.Contains(t, msg, "tls minVersion")
+	assert.Contains(t, msg, "tls cipherSuites")
+	assert.Contains(t, msg, "tls clientAuth")
+
+	err = NewConfig().WithTLS(com.TLSConfig{CertFile: "server.crt", KeyFile: "server.key", ClientAuth: com.TLSClientAuthRequire}).Validate()
+	assert.ErrorContains(t, err, "requires clientCAFile")
+}
+
+func TestConfigValidateAccessLogPolicy(t *testing.T) {
+	err := NewConfig().WithAccessLogPolicy(com.AccessLogPolicy{
+		SampleRate:       com.SampleRate(1.5),
+		RouteSampleRates: map[string]float64{"/users/:id": -1},
+		SkipPaths:        []string{"internal"},
+		SkipMethods:      []string{" "},
+	}).Validate()
+	require.Error(t, err)
+
+	msg := err.Error()
+	assert.Contains(t, msg, "accessLogPolicy sampleRate")
+	assert.Contains(t, msg, `accessLogPolicy routeSampleRates["/users/:id"]`)
+	assert.Contains(t, msg, "accessLogPolicy skipPaths[0]")
+	assert.Contains(t, msg, "accessLogPolicy skipMethods[0]")
+
+	assert.NoError(t, NewConfig().WithAccessLogPolicy(com.AccessLogPolicy{SampleRate: com.SampleRate(0.5), SkipPaths: []string{"/internal/*"}}).Validate())
+}
+
+func TestStrictConfigRefusesToStart(t *testing.T) {
+	config := NewConfig().WithTrustedProxies([]string{"invalid-cidr"}).WithRemoteIPHeaders([]string{"X-Unknown"})
+	engine := NewEngine(config, NewOptions().EnableStrictConfig())
+
+	err := engine.RunContext(context.Background())
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorContains(t, err, "invalid-cidr")
+	assert.ErrorContains(t, err, "X-Unknown")
+	assert.False(t, engine.IsRunning())
+}
+
+func TestNonStrictConfigIgnoresValidationErrors(t *testing.T) {
+	config := NewConfig().WithRemoteIPHeaders([]string{"X-Unknown"})
+	engine := NewEngine(config, NewOptions())
+	assert.NoError(t, engine.initErr)
+}
+
+func TestStrictConfigRejectsPortZero(t *testing.T) {
+	engine := NewEngine(NewConfig().WithPort(0), NewOptions().EnableStrictConfig())
+	assert.ErrorContains(t, engine.initErr, "use ephemeralPort")
+}
+
+func TestNonStrictConfigWarnsOnPortZero(t *testing.T) {
+	logger, buf := newBufferZapLogger()
+	engine := NewEngine(NewConfig().WithPort(0).WithZapLogger(logger), NewOptions())
+	require.NoError(t, engine.initErr)
+	assert.Equal(t, uint16(8080), engine.config.Port)
+	assert.Contains(t, buf.String(), `"message":"port 0 is replaced with the default port, use ephemeralPort to let the system choose a port","port":8080`)
+}